@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/registration"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+var (
+	// errCertKeyAmbiguous returned if name matches more than one cached certificate.
+	errCertKeyAmbiguous = errors.New("more than one cached certificate with the same name")
+	// errNoPEMCertificate returned if cached certificate has no PEM block.
+	errNoPEMCertificate = errors.New("no PEM encoded certificate")
+)
+
+const certKeyHashSize = 8 // bytes of domains hash in certificate key
+
+//nolint:gochecknoglobals
+var keyTypes = map[string]certcrypto.KeyType{
+	"RSA2048": certcrypto.RSA2048,
+	"RSA4096": certcrypto.RSA4096,
+	"EC256":   certcrypto.EC256,
+	"EC384":   certcrypto.EC384,
+}
+
+// CacheStorage is cache location of accounts and issued certificates shared by certificate commands.
+//
+//nolint:staticcheck
+type CacheStorage struct {
+	CacheDir   string `short:"c" long:"cache-dir" env:"CACHE_DIR" description:"Cache location for accounts information. For nas storage it is File Station path (ex: /homes/admin/syno-cli)" default:".cache"`
+	Storage    string `long:"storage" env:"STORAGE" description:"Cache storage: plain local directory, local directory encrypted by passphrase, or directory on Synology" default:"dir" choice:"dir" choice:"age" choice:"nas"`
+	Passphrase string `long:"storage-passphrase" env:"STORAGE_PASSPHRASE" description:"Passphrase for age storage"`
+	store      certStorage
+}
+
+// openStorage opens cache storage.
+func (cs *CacheStorage) openStorage(syno func() *client.Client) error {
+	store, err := newStorage(cs.Storage, cs.CacheDir, cs.Passphrase, syno)
+	if err != nil {
+		return err
+	}
+	cs.store = store
+	return nil
+}
+
+// ACMEAccount is ACME account configuration and cache storage shared by certificate commands.
+//
+//nolint:staticcheck
+type ACMEAccount struct {
+	CacheStorage
+	Email      string `short:"e" long:"email" env:"EMAIL" description:"Email for contact"`
+	ACMEServer string `long:"acme-server" env:"ACME_SERVER" description:"ACME directory URL (ex: Let's Encrypt staging, ZeroSSL, step-ca)" default:"https://acme-v02.api.letsencrypt.org/directory"`
+	EABKeyID   string `long:"eab-kid" env:"EAB_KID" description:"Key identifier for External Account Binding"`
+	EABHMAC    string `long:"eab-hmac" env:"EAB_HMAC" description:"Base64 encoded HMAC key for External Account Binding"`
+	CARoots    string `long:"ca-roots" env:"CA_ROOTS" description:"Path to PEM bundle with additional trusted roots for ACME server"`
+	KeyType    string `long:"key-type" env:"KEY_TYPE" description:"Certificate key type" default:"RSA2048" choice:"RSA2048" choice:"RSA4096" choice:"EC256" choice:"EC384"`
+}
+
+// openStorage validates options and opens cache storage.
+func (ao *ACMEAccount) openStorage(syno func() *client.Client) error {
+	if (ao.EABKeyID == "") != (ao.EABHMAC == "") {
+		return fmt.Errorf("both --eab-kid and --eab-hmac should be set") //nolint:goerr113
+	}
+	return ao.CacheStorage.openStorage(syno)
+}
+
+func (ao *ACMEAccount) getOrCreateAccount(ctx context.Context) (*lego.Client, error) {
+	accountKey := ao.accountKey()
+	account, err := loadAccount(ctx, ao.store, accountKey)
+	if errors.Is(err, os.ErrNotExist) && ao.ACMEServer == lego.LEDirectoryProduction {
+		// accounts created before multi-server support are stored without server
+		account, err = loadAccount(ctx, ao.store, ao.Email+".json")
+	}
+	if err == nil {
+		slog.Info("we are using saved account", "server", ao.ACMEServer)
+		config, err := ao.legoConfig(account)
+		if err != nil {
+			return nil, err
+		}
+		return lego.NewClient(config)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	slog.Info("generating new account", "server", ao.ACMEServer)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &legoAccount{
+		Email: ao.Email,
+		Key:   privateKey,
+	}
+
+	config, err := ao.legoConfig(user)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	var reg *registration.Resource
+	if ao.EABKeyID != "" {
+		reg, err = client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
+			TermsOfServiceAgreed: true,
+			Kid:                  ao.EABKeyID,
+			HmacEncoded:          ao.EABHMAC,
+		})
+	} else {
+		reg, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Registration = reg
+
+	return client, saveJSON(ctx, ao.store, accountKey, user)
+}
+
+func (ao *ACMEAccount) legoConfig(user registration.User) (*lego.Config, error) {
+	config := lego.NewConfig(user)
+	config.CADirURL = ao.ACMEServer
+	config.Certificate.KeyType = keyTypes[ao.KeyType]
+	if ao.CARoots == "" {
+		return config, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("load system cert pool: %w", err)
+	}
+	roots, err := os.ReadFile(ao.CARoots)
+	if err != nil {
+		return nil, fmt.Errorf("read CA roots: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(roots) {
+		return nil, fmt.Errorf("no certificates in CA roots %s", ao.CARoots) //nolint:goerr113
+	}
+	transport, ok := config.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected ACME transport %T", config.HTTPClient.Transport) //nolint:goerr113
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return config, nil
+}
+
+// accountKey is storage key of account information. Accounts are scoped by ACME server and email.
+func (ao *ACMEAccount) accountKey() string {
+	server := ao.ACMEServer
+	if u, err := url.Parse(server); err == nil {
+		server = u.Host + u.Path
+	}
+	safe := strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(strings.Trim(server, "/"))
+	return path.Join("accounts", safe, ao.Email+".json")
+}
+
+type legoAccount struct {
+	Email        string
+	Registration *registration.Resource
+	Key          *ecdsa.PrivateKey `json:"-"`
+}
+
+func (la *legoAccount) GetEmail() string {
+	return la.Email
+}
+
+func (la *legoAccount) GetRegistration() *registration.Resource {
+	return la.Registration
+}
+
+func (la *legoAccount) GetPrivateKey() crypto.PrivateKey {
+	return la.Key
+}
+
+func (la *legoAccount) MarshalJSON() ([]byte, error) {
+	data, err := x509.MarshalECPrivateKey(la.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(serializedLegoAccount{
+		Email:        la.Email,
+		Registration: la.Registration,
+		RawKey:       data,
+	})
+}
+
+func (la *legoAccount) UnmarshalJSON(bytes []byte) error {
+	var acc serializedLegoAccount
+	err := json.Unmarshal(bytes, &acc)
+	if err != nil {
+		return err
+	}
+
+	key, err := x509.ParseECPrivateKey(acc.RawKey)
+	if err != nil {
+		return err
+	}
+	la.Email = acc.Email
+	la.Key = key
+	la.Registration = acc.Registration
+	return nil
+}
+
+func loadAccount(ctx context.Context, store certStorage, key string) (*legoAccount, error) {
+	var acc legoAccount
+	return &acc, loadJSON(ctx, store, key, &acc)
+}
+
+type serializedLegoAccount struct {
+	Email        string
+	Registration *registration.Resource
+	RawKey       []byte
+}
+
+func saveCert(ctx context.Context, store certStorage, key string, resource *certificate.Resource) error {
+	return saveJSON(ctx, store, key, serializedCertificate{
+		Domain:            resource.Domain,
+		CertURL:           resource.CertURL,
+		CertStableURL:     resource.CertStableURL,
+		PrivateKey:        resource.PrivateKey,
+		Certificate:       resource.Certificate,
+		IssuerCertificate: resource.IssuerCertificate,
+		CSR:               resource.CSR,
+	})
+}
+
+func loadCert(ctx context.Context, store certStorage, key string) (*certificate.Resource, error) {
+	var resource serializedCertificate
+	err := loadJSON(ctx, store, key, &resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificate.Resource{
+		Domain:            resource.Domain,
+		CertURL:           resource.CertURL,
+		CertStableURL:     resource.CertStableURL,
+		PrivateKey:        resource.PrivateKey,
+		Certificate:       resource.Certificate,
+		IssuerCertificate: resource.IssuerCertificate,
+		CSR:               resource.CSR,
+	}, nil
+}
+
+// certKey returns storage key for certificate group. Wildcards and path separators in name are replaced by underscore,
+// so hash of sorted lower-cased domains is appended to keep keys of different groups apart.
+func certKey(group CertGroup) string {
+	domains := make([]string, 0, len(group.Domains))
+	for _, domain := range group.Domains {
+		domains = append(domains, strings.ToLower(domain))
+	}
+	slices.Sort(domains)
+	sum := sha256.Sum256([]byte(strings.Join(domains, ",")))
+	return safeCertName(group.Name) + "-" + hex.EncodeToString(sum[:certKeyHashSize]) + ".json"
+}
+
+// legacyCertKey is storage key used before domains hash was added to certKey.
+func legacyCertKey(name string) string {
+	return safeCertName(name) + ".json"
+}
+
+func safeCertName(name string) string {
+	return strings.NewReplacer("*", "_", "/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
+// findCertKey returns storage key of cached certificate by name only (domains are unknown).
+// Certificates cached before domains hash was added to key are found as well.
+func findCertKey(ctx context.Context, store certStorage, name string) (string, error) {
+	keys, err := store.Keys(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list cache: %w", err)
+	}
+	prefix := safeCertName(name) + "-"
+	var found []string
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		hash, ok := strings.CutSuffix(rest, ".json")
+		if !ok || len(hash) != 2*certKeyHashSize {
+			continue
+		}
+		if _, err := hex.DecodeString(hash); err == nil {
+			found = append(found, key)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if slices.Contains(keys, legacyCertKey(name)) {
+			return legacyCertKey(name), nil
+		}
+		return "", fmt.Errorf("certificate %q: %w", name, os.ErrNotExist)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("%w: %q", errCertKeyAmbiguous, name)
+	}
+}
+
+type serializedCertificate struct {
+	Domain            string `json:"domain"`
+	CertURL           string `json:"certUrl"`
+	CertStableURL     string `json:"certStableUrl"`
+	PrivateKey        []byte `json:"privateKey"`
+	Certificate       []byte `json:"certificate"`
+	IssuerCertificate []byte `json:"issuer_certificate"`
+	CSR               []byte `json:"csr"`
+}
+
+func parseCert(cert *certificate.Resource) (*x509.Certificate, error) {
+	info, _ := pem.Decode(cert.Certificate)
+	if info == nil {
+		return nil, errNoPEMCertificate
+	}
+	return x509.ParseCertificate(info.Bytes)
+}
@@ -0,0 +1,420 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	applyCreate  = "create"
+	applyReplace = "replace"
+	applyBind    = "bind"
+	applyDelete  = "delete"
+)
+
+//nolint:staticcheck
+type CertsApply struct {
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	CacheStorage
+	File   string `short:"f" long:"file" env:"FILE" description:"State file with desired certificates" required:"true"`
+	Yes    bool   `short:"y" long:"yes" env:"YES" description:"Do not ask for confirmation"`
+	DryRun bool   `long:"dry-run" env:"DRY_RUN" description:"Show plan and exit"`
+}
+
+// certsState is desired state of certificates in Synology.
+type certsState struct {
+	Default      string        `yaml:"default"`      // name of default certificate
+	Prune        bool          `yaml:"prune"`        // delete certificates not declared in state
+	Certificates []desiredCert `yaml:"certificates"` // desired certificates
+}
+
+// desiredCert is certificate in state file. Exactly one source should be set: acme, bundle, pfx or cert.
+// Relative paths are resolved against state file directory.
+type desiredCert struct {
+	Name        string   `yaml:"name"`
+	ACME        string   `yaml:"acme"`         // certificate name in cert auto cache
+	Bundle      string   `yaml:"bundle"`       // combined PEM (leaf, chain and optionally key)
+	PFX         string   `yaml:"pfx"`          // PKCS#12 archive
+	PFXPassword string   `yaml:"pfx_password"` // password for PKCS#12 archive
+	Cert        string   `yaml:"cert"`         // PEM certificate
+	CA          string   `yaml:"ca"`           // PEM intermediate certificates
+	Key         string   `yaml:"key"`          // PEM private key, required for cert and bundle without key
+	Services    []string `yaml:"services"`     // display name, service name or subscriber/service
+}
+
+// applyPlan is set of changes required to converge Synology to desired state.
+type applyPlan struct {
+	Uploads  []applyUpload
+	Bindings []applyBinding
+	Deletes  []client.Certificate
+	Steps    []applyStep // human-readable plan
+}
+
+type applyUpload struct {
+	Name      string
+	Bundle    *client.CertBundle
+	ID        string // existent certificate ID, empty for new certificate
+	AsDefault bool
+}
+
+type applyBinding struct {
+	Service client.BoundService
+	Name    string // desired certificate name
+}
+
+type applyStep struct {
+	Action  string
+	Name    string
+	ID      string
+	Details string
+}
+
+func (cmd *CertsApply) Execute([]string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	state, err := readState(cmd.File)
+	if err != nil {
+		return fmt.Errorf("read state %s: %w", cmd.File, err)
+	}
+
+	if err := cmd.openStorage(cmd.Client); err != nil {
+		return err
+	}
+
+	bundles := make(map[string]*client.CertBundle, len(state.Certificates))
+	for _, dc := range state.Certificates {
+		bundle, err := cmd.loadDesired(ctx, filepath.Dir(cmd.File), dc)
+		if err != nil {
+			return fmt.Errorf("load certificate %s: %w", dc.Name, err)
+		}
+		bundles[dc.Name] = bundle
+	}
+
+	syno := cmd.Client()
+	list, err := syno.ListCerts(ctx)
+	if err != nil {
+		return fmt.Errorf("list certificates in Synology: %w", err)
+	}
+
+	plan, err := planApply(state, bundles, list)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Steps) == 0 {
+		slog.Info("certificates are up to date")
+		return nil
+	}
+	if err := plan.show(); err != nil {
+		return err
+	}
+	if cmd.DryRun {
+		return nil
+	}
+	if !cmd.Yes && !confirm(fmt.Sprintf("Apply %d change(s)?", len(plan.Steps))) {
+		slog.Info("cancelled")
+		return nil
+	}
+
+	return plan.apply(ctx, syno, list)
+}
+
+func readState(file string) (*certsState, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var state certsState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	var names = make(map[string]bool, len(state.Certificates))
+	for _, dc := range state.Certificates {
+		if dc.Name == "" {
+			return nil, fmt.Errorf("certificate name is not set") //nolint:goerr113
+		}
+		if names[dc.Name] {
+			return nil, fmt.Errorf("certificate %q declared more than once", dc.Name) //nolint:goerr113
+		}
+		names[dc.Name] = true
+		if countTrue(dc.ACME != "", dc.Bundle != "", dc.PFX != "", dc.Cert != "") != 1 {
+			return nil, fmt.Errorf("certificate %q: exactly one of acme, bundle, pfx or cert should be set", dc.Name) //nolint:goerr113
+		}
+	}
+	if state.Default != "" && !names[state.Default] {
+		return nil, fmt.Errorf("default certificate %q is not declared", state.Default) //nolint:goerr113
+	}
+	return &state, nil
+}
+
+// loadDesired reads certificate, key and chain from cert auto cache or local files.
+func (cmd *CertsApply) loadDesired(ctx context.Context, baseDir string, dc desiredCert) (*client.CertBundle, error) {
+	if dc.ACME != "" {
+		key, err := findCertKey(ctx, cmd.store, dc.ACME)
+		if err != nil {
+			return nil, fmt.Errorf("load from cache: %w", err)
+		}
+		res, err := loadCert(ctx, cmd.store, key)
+		if err != nil {
+			return nil, fmt.Errorf("load from cache: %w", err)
+		}
+		return client.ParsePEMBundle(append(append([]byte{}, res.Certificate...), res.PrivateKey...))
+	}
+
+	readFile := func(file string) ([]byte, error) {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(baseDir, file)
+		}
+		return os.ReadFile(file)
+	}
+
+	var bundle *client.CertBundle
+	switch {
+	case dc.PFX != "":
+		data, err := readFile(dc.PFX)
+		if err != nil {
+			return nil, err
+		}
+		return client.ParsePKCS12(data, dc.PFXPassword)
+	case dc.Bundle != "":
+		data, err := readFile(dc.Bundle)
+		if err != nil {
+			return nil, err
+		}
+		bundle, err = client.ParsePEMBundle(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		cert, err := readFile(dc.Cert)
+		if err != nil {
+			return nil, err
+		}
+		bundle = &client.CertBundle{Cert: cert}
+		if dc.CA != "" {
+			bundle.Chain, err = readFile(dc.CA)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(bundle.Key) > 0 {
+		return bundle, nil
+	}
+	if dc.Key == "" {
+		return nil, fmt.Errorf("key is not set") //nolint:goerr113
+	}
+	key, err := readFile(dc.Key)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Key = key
+	return bundle, nil
+}
+
+// planApply compares desired state with certificates in Synology.
+//
+// Certificate is uploaded if it is missing, broken, differs from desired or should become default.
+// Services are re-bound if they use another certificate. With prune enabled, undeclared certificates are
+// deleted if they are deletable, not default and all their services are re-bound by plan.
+func planApply(state *certsState, bundles map[string]*client.CertBundle, list []client.Certificate) (*applyPlan, error) {
+	var plan applyPlan
+	var ids = make(map[string]string, len(state.Certificates)) // desired name -> existent ID
+	for _, dc := range state.Certificates {
+		found := client.FindCertsByName(list, dc.Name)
+		if len(found) > 1 {
+			return nil, fmt.Errorf("%w: %q", client.ErrCertAmbiguous, dc.Name)
+		}
+		asDefault := state.Default == dc.Name
+		upload := applyUpload{Name: dc.Name, Bundle: bundles[dc.Name], AsDefault: asDefault}
+		if len(found) == 0 {
+			plan.Uploads = append(plan.Uploads, upload)
+			plan.Steps = append(plan.Steps, applyStep{Action: applyCreate, Name: dc.Name, Details: defaultNote(asDefault)})
+			continue
+		}
+		current := found[0]
+		ids[dc.Name] = current.ID
+		reason, err := replaceReason(current, upload)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %s: %w", dc.Name, err)
+		}
+		if reason != "" {
+			upload.ID = current.ID
+			plan.Uploads = append(plan.Uploads, upload)
+			plan.Steps = append(plan.Steps, applyStep{Action: applyReplace, Name: dc.Name, ID: current.ID, Details: reason})
+		}
+	}
+
+	services := client.ListServices(list)
+	var boundTo = make(map[string]string) // service ref -> desired name
+	for _, dc := range state.Certificates {
+		for _, ref := range dc.Services {
+			var matched bool
+			for _, svc := range services {
+				if !svc.Matches(ref) {
+					continue
+				}
+				matched = true
+				if other, ok := boundTo[svc.Ref()]; ok && other != dc.Name {
+					return nil, fmt.Errorf("service %s declared for both %s and %s", svc.Ref(), other, dc.Name) //nolint:goerr113
+				}
+				boundTo[svc.Ref()] = dc.Name
+				if id, ok := ids[dc.Name]; ok && id == svc.CertificateID {
+					continue
+				}
+				plan.Bindings = append(plan.Bindings, applyBinding{Service: svc, Name: dc.Name})
+				plan.Steps = append(plan.Steps, applyStep{Action: applyBind, Name: dc.Name, ID: ids[dc.Name], Details: svc.Ref() + " (" + svc.DisplayName + ")"})
+			}
+			if !matched {
+				return nil, fmt.Errorf("certificate %s: service %q not found", dc.Name, ref) //nolint:goerr113
+			}
+		}
+	}
+
+	if !state.Prune {
+		return &plan, nil
+	}
+	for _, crt := range list {
+		if bundles[crt.Description] != nil {
+			continue // declared
+		}
+		if !crt.UserDeletable || (crt.IsDefault && state.Default == "") {
+			continue
+		}
+		var inUse int
+		for _, svc := range crt.Services {
+			bs := client.BoundService{Service: svc}
+			if _, ok := boundTo[bs.Ref()]; !ok {
+				inUse++
+			}
+		}
+		if inUse > 0 {
+			slog.Warn("undeclared certificate still used by services, skipped", "name", crt.Description, "id", crt.ID, "services", inUse)
+			continue
+		}
+		plan.Deletes = append(plan.Deletes, crt)
+		plan.Steps = append(plan.Steps, applyStep{Action: applyDelete, Name: crt.Description, ID: crt.ID, Details: "not declared"})
+	}
+	return &plan, nil
+}
+
+// replaceReason explains why existent certificate should be replaced. Empty result means it is up to date.
+func replaceReason(current client.Certificate, upload applyUpload) (string, error) {
+	if current.IsBroken {
+		return "broken", nil
+	}
+	certs, err := client.ParseCertificates(upload.Bundle.Cert)
+	if err != nil {
+		return "", err
+	}
+	if !current.Matches(certs[0]) {
+		return "changed", nil
+	}
+	if upload.AsDefault && !current.IsDefault {
+		return "set as default", nil
+	}
+	return "", nil
+}
+
+func defaultNote(asDefault bool) string {
+	if asDefault {
+		return "as default"
+	}
+	return ""
+}
+
+// apply executes plan: uploads certificates, re-binds services and deletes undeclared certificates.
+// Synology has no transactions, so plan could be applied partially: error names failed step and steps already applied.
+func (plan *applyPlan) apply(ctx context.Context, syno *client.Client, list []client.Certificate) error {
+	var ids = make(map[string]string)
+	for _, crt := range list {
+		ids[crt.Description] = crt.ID
+	}
+	var applied []string
+	failed := func(step string, err error) error {
+		done := "nothing"
+		if len(applied) > 0 {
+			done = strings.Join(applied, ", ")
+		}
+		return fmt.Errorf("%s: %w (already applied: %s)", step, err, done)
+	}
+
+	for _, up := range plan.Uploads {
+		draft := up.Bundle.NewCertificate(up.Name, up.AsDefault)
+		draft.Mode, draft.ID = client.UploadCreateOnly, ""
+		step := "create " + up.Name
+		if up.ID != "" {
+			draft.Mode, draft.ID = client.UploadReplaceByID, up.ID
+			step = "replace " + up.Name + " (" + up.ID + ")"
+		}
+		info, err := syno.UploadCert(ctx, draft)
+		if err != nil {
+			return failed(step, err)
+		}
+		applied = append(applied, step)
+		ids[up.Name] = info.CertificateID
+		slog.Info("certificate uploaded", "name", up.Name, "certificate_id", info.CertificateID, "server_restarted", info.ServerRestarted)
+	}
+
+	if len(plan.Bindings) > 0 {
+		var bindings = make([]client.ServiceBinding, 0, len(plan.Bindings))
+		var services = make([]string, 0, len(plan.Bindings))
+		for _, b := range plan.Bindings {
+			bindings = append(bindings, client.ServiceBinding{
+				Service: b.Service.Service,
+				OldID:   b.Service.CertificateID,
+				ID:      ids[b.Name],
+			})
+			services = append(services, b.Service.DisplayName+" to "+b.Name)
+		}
+		step := "bind " + strings.Join(services, ", ")
+		info, err := syno.BindServices(ctx, bindings)
+		if err != nil {
+			return failed(step, err)
+		}
+		applied = append(applied, step)
+		slog.Info("services bound", "services", len(bindings), "server_restarted", info.ServerRestarted)
+	}
+
+	for _, crt := range plan.Deletes {
+		step := "delete " + crt.Description + " (" + crt.ID + ")"
+		info, err := syno.DeleteCertByID(ctx, crt.ID)
+		if err != nil {
+			return failed(step, err)
+		}
+		applied = append(applied, step)
+		slog.Info("certificate deleted", "name", crt.Description, "certificate_id", crt.ID, "server_restarted", info.ServerRestarted)
+	}
+	return nil
+}
+
+//nolint:gomnd
+func (plan *applyPlan) show() error {
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"Action", "\t",
+		"Name", "\t",
+		"ID", "\t",
+		"Details", "\t",
+	)
+	for _, step := range plan.Steps {
+		_, _ = fmt.Fprintln(tw,
+			strings.ToUpper(step.Action), "\t",
+			step.Name, "\t",
+			step.ID, "\t",
+			step.Details, "\t",
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestReadState(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+		count   int
+	}{
+		{name: "valid", count: 2, content: `
+default: web
+prune: true
+certificates:
+  - name: web
+    acme: example
+    services: [FTP]
+  - name: mail
+    cert: mail.crt
+    key: mail.key
+`},
+		{name: "no name", err: "name is not set", content: `
+certificates:
+  - acme: example
+`},
+		{name: "duplicate", err: `"web" declared more than once`, content: `
+certificates:
+  - name: web
+    acme: example
+  - name: web
+    bundle: web.pem
+`},
+		{name: "no source", err: "exactly one of", content: `
+certificates:
+  - name: web
+`},
+		{name: "many sources", err: "exactly one of", content: `
+certificates:
+  - name: web
+    acme: example
+    pfx: web.pfx
+`},
+		{name: "unknown default", err: `default certificate "mail" is not declared`, content: `
+default: mail
+certificates:
+  - name: web
+    acme: example
+`},
+		{name: "invalid yaml", err: "yaml", content: `certificates: {`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "state.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0600))
+			state, err := readState(file)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, state.Certificates, tt.count)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readState(filepath.Join(t.TempDir(), "state.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestReplaceReason(t *testing.T) {
+	certPEM, _ := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	otherPEM, _ := testCertPEM(t, time.Now().Add(2*time.Hour), "example.com")
+	current := synoCert(t, "abc", "web", certPEM)
+
+	tests := []struct {
+		name    string
+		current func(crt *client.Certificate)
+		cert    []byte
+		def     bool
+		reason  string
+		err     bool
+	}{
+		{name: "up to date", cert: certPEM},
+		{name: "broken", cert: certPEM, current: func(crt *client.Certificate) { crt.IsBroken = true }, reason: "broken"},
+		{name: "changed", cert: otherPEM, reason: "changed"},
+		{name: "set as default", cert: certPEM, def: true, reason: "set as default"},
+		{name: "already default", cert: certPEM, def: true, current: func(crt *client.Certificate) { crt.IsDefault = true }},
+		{name: "invalid certificate", cert: []byte("garbage"), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crt := current
+			if tt.current != nil {
+				tt.current(&crt)
+			}
+			reason, err := replaceReason(crt, applyUpload{Name: "web", Bundle: &client.CertBundle{Cert: tt.cert}, AsDefault: tt.def})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	webPEM, _ := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	otherPEM, _ := testCertPEM(t, time.Now().Add(2*time.Hour), "example.com")
+	bundles := map[string]*client.CertBundle{"web": {Cert: webPEM}}
+	ftp := client.Service{DisplayName: "FTP", Service: "ftpd", Subscriber: "system"}
+	dsm := client.Service{DisplayName: "DSM Desktop Service", Service: "default", Subscriber: "system"}
+
+	web := synoCert(t, "web-id", "web", webPEM)
+	changed := synoCert(t, "web-id", "web", otherPEM)
+	old := synoCert(t, "old-id", "old", otherPEM)
+	old.UserDeletable = true
+	oldWithFTP := old
+	oldWithFTP.Services = []client.Service{ftp}
+	oldDefault := old
+	oldDefault.IsDefault = true
+	system := old
+	system.UserDeletable = false
+
+	tests := []struct {
+		name  string
+		state certsState
+		list  []client.Certificate
+		steps []applyStep
+		err   string
+	}{
+		{
+			name:  "create missing",
+			state: certsState{Certificates: []desiredCert{{Name: "web"}}},
+			steps: []applyStep{{Action: applyCreate, Name: "web"}},
+		},
+		{
+			name:  "create as default",
+			state: certsState{Default: "web", Certificates: []desiredCert{{Name: "web"}}},
+			steps: []applyStep{{Action: applyCreate, Name: "web", Details: "as default"}},
+		},
+		{
+			name:  "up to date",
+			state: certsState{Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web},
+		},
+		{
+			name:  "replace changed",
+			state: certsState{Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{changed},
+			steps: []applyStep{{Action: applyReplace, Name: "web", ID: "web-id", Details: "changed"}},
+		},
+		{
+			name:  "ambiguous",
+			state: certsState{Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, changed},
+			err:   client.ErrCertAmbiguous.Error(),
+		},
+		{
+			name:  "bind service",
+			state: certsState{Certificates: []desiredCert{{Name: "web", Services: []string{"ftp"}}}},
+			list:  []client.Certificate{web, oldWithFTP},
+			steps: []applyStep{{Action: applyBind, Name: "web", ID: "web-id", Details: "system/ftpd (FTP)"}},
+		},
+		{
+			name:  "service already bound",
+			state: certsState{Certificates: []desiredCert{{Name: "web", Services: []string{"system/ftpd"}}}},
+			list:  []client.Certificate{withServices(web, ftp), old},
+		},
+		{
+			name:  "unknown service",
+			state: certsState{Certificates: []desiredCert{{Name: "web", Services: []string{"smb"}}}},
+			list:  []client.Certificate{web, oldWithFTP},
+			err:   `service "smb" not found`,
+		},
+		{
+			name:  "service declared twice",
+			state: certsState{Certificates: []desiredCert{{Name: "web", Services: []string{"FTP"}}, {Name: "mail", Services: []string{"ftpd"}}}},
+			list:  []client.Certificate{web, oldWithFTP},
+			err:   "declared for both web and mail",
+		},
+		{
+			name:  "keep undeclared without prune",
+			state: certsState{Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, old},
+		},
+		{
+			name:  "prune undeclared",
+			state: certsState{Prune: true, Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, old},
+			steps: []applyStep{{Action: applyDelete, Name: "old", ID: "old-id", Details: "not declared"}},
+		},
+		{
+			name:  "prune re-bound",
+			state: certsState{Prune: true, Certificates: []desiredCert{{Name: "web", Services: []string{"FTP"}}}},
+			list:  []client.Certificate{web, oldWithFTP},
+			steps: []applyStep{
+				{Action: applyBind, Name: "web", ID: "web-id", Details: "system/ftpd (FTP)"},
+				{Action: applyDelete, Name: "old", ID: "old-id", Details: "not declared"},
+			},
+		},
+		{
+			name:  "prune skips used",
+			state: certsState{Prune: true, Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, withServices(oldWithFTP, dsm)},
+		},
+		{
+			name:  "prune skips default",
+			state: certsState{Prune: true, Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, oldDefault},
+		},
+		{
+			name:  "prune replaced default",
+			state: certsState{Prune: true, Default: "web", Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, oldDefault},
+			steps: []applyStep{
+				{Action: applyReplace, Name: "web", ID: "web-id", Details: "set as default"},
+				{Action: applyDelete, Name: "old", ID: "old-id", Details: "not declared"},
+			},
+		},
+		{
+			name:  "prune skips not deletable",
+			state: certsState{Prune: true, Certificates: []desiredCert{{Name: "web"}}},
+			list:  []client.Certificate{web, system},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planApply(&tt.state, bundles, tt.list)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.steps, plan.Steps)
+		})
+	}
+}
+
+func TestApplyPlan_apply(t *testing.T) {
+	webPEM, webKey := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	mailPEM, mailKey := testCertPEM(t, time.Now().Add(time.Hour), "mail.example.com")
+	otherPEM, _ := testCertPEM(t, time.Now().Add(2*time.Hour), "example.com")
+	bundles := map[string]*client.CertBundle{"web": {Cert: webPEM, Key: webKey}, "mail": {Cert: mailPEM, Key: mailKey}}
+	ftp := client.Service{DisplayName: "FTP", Service: "ftpd", Subscriber: "system"}
+	old := withServices(synoCert(t, "old-id", "old", otherPEM), ftp)
+	old.UserDeletable = true
+	list := []client.Certificate{synoCert(t, "web-id", "web", otherPEM), old}
+	state := certsState{Prune: true, Certificates: []desiredCert{{Name: "web", Services: []string{"FTP"}}, {Name: "mail"}}}
+
+	tests := []struct {
+		name  string
+		fail  string // API method which fails
+		calls []string
+		err   string
+	}{
+		{
+			name:  "applied",
+			calls: []string{"import web web-id", "import mail ", "set", "delete old-id"},
+		},
+		{
+			name:  "failed upload",
+			fail:  "import",
+			calls: []string{"import web web-id"},
+			err:   "replace web (web-id): response: API error code: 100 (already applied: nothing)",
+		},
+		{
+			name:  "failed binding",
+			fail:  "set",
+			calls: []string{"import web web-id", "import mail ", "set"},
+			err:   "bind FTP to web: response: API error code: 100 (already applied: replace web (web-id), create mail)",
+		},
+		{
+			name:  "failed delete",
+			fail:  "delete",
+			calls: []string{"import web web-id", "import mail ", "set", "delete old-id"},
+			err:   "delete old (old-id): response: API error code: 100 (already applied: replace web (web-id), create mail, bind FTP to web)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls, unexpected []string
+			syno := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+				api, method := request.FormValue("api"), request.FormValue("method")
+				call := method
+				switch {
+				case api == "SYNO.Core.Certificate.CRT" && method == "list":
+					return certsList(t, list...)
+				case api == "SYNO.Core.Certificate" && method == "import":
+					call += " " + request.FormValue("desc") + " " + request.FormValue("id")
+				case api == "SYNO.Core.Certificate.Service" && method == "set":
+					var bindings []client.ServiceBinding
+					assert.NoError(t, json.Unmarshal([]byte(request.FormValue("settings")), &bindings))
+					assert.Equal(t, []client.ServiceBinding{{Service: ftp, OldID: "old-id", ID: "web-id"}}, bindings)
+				case api == "SYNO.Core.Certificate.CRT" && method == "delete":
+					call += " " + strings.Trim(request.FormValue("ids"), `[]"`)
+				default:
+					unexpected = append(unexpected, api+" "+method)
+					return httpStatus(http.StatusInternalServerError)
+				}
+				calls = append(calls, call)
+				if method == tt.fail {
+					return apiErrorCode(100)
+				}
+				if method == "import" {
+					return map[string]any{"id": map[string]string{"web": "web-id", "mail": "mail-id"}[request.FormValue("desc")]}
+				}
+				return map[string]any{"restart_httpd": false}
+			})
+
+			plan, err := planApply(&state, bundles, list)
+			require.NoError(t, err)
+			err = plan.apply(context.Background(), syno.Client(), list)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.calls, calls)
+			assert.Empty(t, unexpected)
+		})
+	}
+}
+
+// synoCert is certificate in Synology matching PEM certificate.
+func synoCert(t *testing.T, id, name string, certPEM []byte) client.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	crt, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return client.Certificate{
+		ID:          id,
+		Description: name,
+		Subject:     client.Subject{CommonName: crt.Subject.CommonName, SubAltName: crt.DNSNames},
+		ValidFrom:   client.CTime(crt.NotBefore.Truncate(time.Second)),
+		ValidTill:   client.CTime(crt.NotAfter.Truncate(time.Second)),
+	}
+}
+
+func withServices(crt client.Certificate, services ...client.Service) client.Certificate {
+	crt.Services = append(append([]client.Service{}, crt.Services...), services...)
+	return crt
+}
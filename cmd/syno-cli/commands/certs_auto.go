@@ -3,359 +3,565 @@ package commands
 import (
 	"bytes"
 	"context"
-	"crypto"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/x509"
-	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/providers/dns"
-	"github.com/go-acme/lego/v4/registration"
+	"github.com/go-acme/lego/v4/providers/http/webroot"
 
 	"github.com/reddec/syno-cli/pkg/client"
 )
 
+const (
+	challengeDNS01     = "dns-01"
+	challengeHTTP01    = "http-01"
+	challengeTLSALPN01 = "tls-alpn-01"
+)
+
 //nolint:staticcheck
 type CertsAuto struct {
-	SynoClient  `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
-	CacheDir    string        `short:"c" long:"cache-dir" env:"CACHE_DIR" description:"Cache location for accounts information" default:".cache"`
-	RenewBefore time.Duration `short:"r" long:"renew-before" env:"RENEW_BEFORE" description:"Renew certificate time reserve" default:"720h"`
-	Email       string        `short:"e" long:"email" env:"EMAIL" description:"Email for contact"`
-	Provider    string        `short:"p" long:"provider" env:"PROVIDER" description:"DNS challenge provider" required:"true" `
-	DNS         []string      `short:"D" long:"dns" env:"DNS" env-delim:","  description:"Custom resolvers" default:"8.8.8.8"`
-	Timeout     time.Duration `short:"t" long:"timeout" env:"TIMEOUT" description:"DNS challenge timeout" default:"1m"`
-	Domains     []string      `short:"d" long:"domains" env:"DOMAINS" env-delim:","  description:"Domains names to issue" required:"true"`
+	SynoClient    `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Hooks         `group:"Hooks"`
+	Notifications `group:"Notifications"`
+	ACMEAccount
+	RenewBefore  time.Duration `short:"r" long:"renew-before" env:"RENEW_BEFORE" description:"Renew certificate time reserve" default:"720h"`
+	Challenge    string        `long:"challenge" env:"CHALLENGE" description:"ACME challenge type" default:"dns-01" choice:"dns-01" choice:"http-01" choice:"tls-alpn-01"`
+	Provider     string        `short:"p" long:"provider" env:"PROVIDER" description:"DNS challenge provider, required for dns-01"`
+	Webroot      string        `long:"webroot" env:"WEBROOT" description:"Directory served by existing web server for http-01 challenge. If not set, built-in listener will be used"`
+	HTTPListen   string        `long:"http-listen" env:"HTTP_LISTEN" description:"Built-in listener address for http-01 challenge" default:":80"`
+	TLSListen    string        `long:"tls-listen" env:"TLS_LISTEN" description:"Built-in listener address for tls-alpn-01 challenge" default:":443"`
+	DNS          []string      `short:"D" long:"dns" env:"DNS" env-delim:","  description:"Custom resolvers" default:"8.8.8.8"`
+	Timeout      time.Duration `short:"t" long:"timeout" env:"TIMEOUT" description:"DNS challenge timeout" default:"1m"`
+	Domains      []string      `short:"d" long:"domains" env:"DOMAINS" env-delim:","  description:"Domains names to issue, one certificate per domain"`
+	ForcePush    bool          `long:"force-push" env:"FORCE_PUSH" description:"Push certificates to Synology even if they are up to date"`
+	Once         bool          `long:"once" env:"ONCE" description:"Run single issue/renew/push cycle and exit; exit code is non-zero on failure"`
+	Interval     time.Duration `long:"interval" env:"INTERVAL" description:"Interval between checks" default:"1h"`
+	DryRun       bool          `long:"dry-run" env:"DRY_RUN" description:"Report what would be issued, renewed or pushed without calling ACME server or Synology. Implies --once"`
+	Certs        []CertGroup   `long:"cert" env:"CERTS" env-delim:";" description:"Certificate covering multiple domains in format name=domain1,domain2 (ex: example=example.com,*.example.com)"`
+	RenewJitter  time.Duration `long:"renew-jitter" env:"RENEW_JITTER" description:"Spread renewal time randomly (stable per host and certificate) up to this duration earlier than --renew-before" default:"24h"`
+	NoARI        bool          `long:"no-ari" env:"NO_ARI" description:"Do not query ACME Renewal Information (suggested renewal window) from CA"`
+	NoOCSP       bool          `long:"no-ocsp" env:"NO_OCSP" description:"Do not check certificate revocation status by OCSP"`
+	StatusListen string        `long:"status-listen" env:"STATUS_LISTEN" description:"Address for HTTP server with /healthz and /status endpoints (ex: :8080). Disabled if not set"`
+	ari          *ariClient
+	status       *autoStatus
 }
 
-func (lc *CertsAuto) Execute([]string) error {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
-	defer cancel()
+// CertGroup is set of domains covered by single certificate.
+type CertGroup struct {
+	Name    string   // certificate name in cache and in Synology
+	Domains []string // domains (SAN), the first one is used as common name
+}
 
-	account, err := lc.getOrCreateAccount()
-	if err != nil {
-		return err
+// UnmarshalFlag parses group from name=domain1,domain2 or single domain.
+func (cg *CertGroup) UnmarshalFlag(value string) error {
+	name, list, ok := strings.Cut(value, "=")
+	if !ok {
+		list = value
 	}
-
-	slog.Info("setting challenger", "provider", lc.Provider)
-	if err := lc.setupChallenge(account); err != nil {
-		return err
+	var domains []string
+	for _, domain := range strings.Split(list, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
 	}
+	if len(domains) == 0 {
+		return fmt.Errorf("no domains in certificate group %q", value) //nolint:goerr113
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = domains[0]
+	}
+	cg.Name = name
+	cg.Domains = domains
+	return nil
+}
 
-	slog.Info("start initial setup")
-
-	for {
-		slog.Info("issuing or renewing certificates if needed")
-		if list, err := lc.issueOrRenewCerts(ctx, account); err != nil {
-			slog.Error("failed issue certs", "error", err)
-		} else if err := lc.pushToSynology(ctx, list); err != nil {
-			slog.Error("failed push to Synology", "error", err)
-		}
-		slog.Info("done, next check after 1 hour")
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-time.After(time.Hour):
+// same checks that certificate covers exactly the same domains as group. Domains are case-insensitive.
+func (cg *CertGroup) same(crt *x509.Certificate) bool {
+	actual := certcrypto.ExtractDomains(crt)
+	if len(actual) != len(cg.Domains) {
+		return false
+	}
+	for i, domain := range actual {
+		actual[i] = strings.ToLower(domain)
+	}
+	for _, domain := range cg.Domains {
+		if !slices.Contains(actual, strings.ToLower(domain)) {
+			return false
 		}
 	}
+	return true
 }
 
-func (lc *CertsAuto) issueOrRenewCerts(ctx context.Context, lgc *lego.Client) ([]*certificate.Resource, error) {
-	var certs []*certificate.Resource
+// groups returns all requested certificates: each domain from --domains as standalone group and groups from --cert.
+func (lc *CertsAuto) groups() []CertGroup {
+	var ans = make([]CertGroup, 0, len(lc.Domains)+len(lc.Certs))
 	for _, domain := range lc.Domains {
-		cert, err := loadCert(lc.CacheDir, domain)
-		if errors.Is(err, os.ErrNotExist) {
-			slog.Info("issuing new certificate", "domain", domain, "reason", "no certificate")
-			// issue
-			cert, err = lc.issueCert(domain, lgc)
-			if err != nil {
-				return nil, fmt.Errorf("issue new certificate for %s: %w", domain, err)
-			}
-		} else if err != nil {
-			// something happen during load
-			return certs, err
-		} else if crt, err := parseCert(cert); err != nil {
-			// parsing failed
-			return certs, err
-		} else if time.Now().After(crt.NotAfter) {
-			slog.Info("issuing new certificate", "domain", domain, "reason", "the old one expired")
-			// expired
-			// issue
-			cert, err = lc.issueCert(domain, lgc)
-			if err != nil {
-				return nil, fmt.Errorf("issue new certificate for %s: %w", domain, err)
-			}
-		} else if time.Until(crt.NotAfter) <= lc.RenewBefore {
-			// renew if soon expired
-			cert, err = lc.renewCert(cert, lgc)
-			if err != nil {
-				return nil, fmt.Errorf("issue new certificate for %s: %w", domain, err)
-			}
-		}
-		certs = append(certs, cert)
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
+		ans = append(ans, CertGroup{Name: domain, Domains: []string{domain}})
 	}
+	return append(ans, lc.Certs...)
+}
 
-	return certs, nil
+// managedCert is issued certificate for group.
+type managedCert struct {
+	Group           CertGroup
+	Resource        *certificate.Resource
+	Action          string // what happened with certificate during cycle: actionKeep, actionIssue or actionRenew
+	CertificateID   string // ID in Synology, set only if certificate pushed
+	ServerRestarted bool   // Synology web server restarted after push
 }
 
-func (lc *CertsAuto) pushToSynology(ctx context.Context, certs []*certificate.Resource) error {
-	syno := lc.SynoClient.Client()
+func (lc *CertsAuto) Execute([]string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
 
-	knownCerts, err := syno.ListCerts(ctx)
-	if err != nil {
+	if len(lc.groups()) == 0 {
+		return fmt.Errorf("at least one of --domains or --cert should be set") //nolint:goerr113
+	}
+	if err := lc.checkWildcards(); err != nil {
 		return err
 	}
-
-	var index = make(map[string]client.Certificate)
-	for _, c := range knownCerts {
-		index[c.Description] = c
+	if lc.DryRun && lc.Storage == storageNAS {
+		// opening nas storage logs in to DSM, but dry run should not touch Synology
+		slog.Warn("dry run: cache on Synology is not read, certificates are reported as not cached")
+		lc.store = emptyStorage{}
+	} else if err := lc.openStorage(lc.SynoClient.Client); err != nil {
+		return err
 	}
-
-	for _, res := range certs {
-		slog.Info("pushing certs to Synology", "domain", res.Domain)
-		status, err := syno.UploadCert(ctx, client.NewCertificate{
-			Name: res.Domain,
-			Cert: bytes.NewReader(res.Certificate),
-			CA:   bytes.NewReader(res.IssuerCertificate),
-			Key:  bytes.NewReader(res.PrivateKey),
-		})
-		if err != nil {
-			return fmt.Errorf("push to synology for domain %s: %w", res.Domain, err)
-		}
-		slog.Info("certificate uploaded", "certificate_id", status.CertificateID, "server_restarted", status.ServerRestarted)
+	lc.status = newAutoStatus(lc.groups(), lc.HookFailures)
+	if err := lc.setupNotifications(lc.Client); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func (lc *CertsAuto) issueCert(domain string, lgc *lego.Client) (*certificate.Resource, error) {
-	request, err := lgc.Certificate.Obtain(certificate.ObtainRequest{
-		Domains: []string{domain},
-		Bundle:  true,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create certificate request for: %w", err)
+	if lc.DryRun {
+		slog.Info("dry run: nothing will be issued or pushed")
+		return lc.cycle(ctx, nil)
 	}
-	return request, saveCert(lc.CacheDir, request)
-}
 
-func (lc *CertsAuto) renewCert(res *certificate.Resource, lgc *lego.Client) (*certificate.Resource, error) {
-	ng, err := lgc.Certificate.Renew(*res, true, false, "")
+	provider, err := lc.challengeProvider()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return ng, saveCert(lc.CacheDir, ng)
-}
 
-func (lc *CertsAuto) setupChallenge(lgc *lego.Client) error {
-	provider, err := dns.NewDNSChallengeProviderByName(lc.Provider)
+	account, err := lc.getOrCreateAccount(ctx)
 	if err != nil {
 		return err
 	}
 
-	var opts = []dns01.ChallengeOption{
-		dns01.AddDNSTimeout(lc.Timeout),
-	}
-	if len(lc.DNS) > 0 {
-		opts = append(opts, dns01.AddRecursiveNameservers(lc.DNS))
+	if !lc.NoARI {
+		config, err := lc.legoConfig(nil)
+		if err != nil {
+			return err
+		}
+		lc.ari = newARIClient(config)
 	}
-	return lgc.Challenge.SetDNS01Provider(provider, opts...)
-}
 
-func (lc *CertsAuto) getOrCreateAccount() (*lego.Client, error) {
-	accountFile := filepath.Join(lc.CacheDir, lc.Email+".json")
-	account, err := loadAccount(accountFile)
-	if err == nil {
-		slog.Info("we are using saved account")
-		return lego.NewClient(lego.NewConfig(account))
+	slog.Info("setting challenger", "challenge", lc.Challenge, "provider", lc.Provider)
+	if err := lc.setupChallenge(account, provider); err != nil {
+		return err
 	}
 
-	if !errors.Is(err, os.ErrNotExist) {
-		return nil, err
+	if lc.StatusListen != "" && !lc.Once {
+		srv, err := lc.status.serve(lc.StatusListen)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
 	}
-	slog.Info("generating new account")
 
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		return nil, err
+	forceCycle := make(chan os.Signal, 1)
+	signal.Notify(forceCycle, syscall.SIGHUP)
+	defer signal.Stop(forceCycle)
+
+	slog.Info("start initial setup")
+
+	for {
+		err := lc.cycle(ctx, account)
+		if lc.Once {
+			if failures := lc.HookFailures(); err == nil && failures > 0 {
+				err = fmt.Errorf("%d hook(s) failed", failures) //nolint:goerr113
+			}
+			return err
+		}
+		slog.Info("done, next check after", "interval", lc.Interval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-forceCycle:
+			slog.Info("cycle forced by signal")
+		case <-time.After(lc.Interval):
+		}
 	}
+}
 
-	user := &legoAccount{
-		Email: lc.Email,
-		Key:   privateKey,
+// cycle issues or renews certificates and pushes them to Synology. In dry-run mode lego client is nil.
+func (lc *CertsAuto) cycle(ctx context.Context, lgc *lego.Client) error {
+	slog.Info("issuing or renewing certificates if needed")
+	list, issueErr := lc.issueOrRenewCerts(ctx, lgc)
+	if issueErr != nil {
+		slog.Error("failed issue certs", "error", issueErr)
+		if ctx.Err() != nil {
+			return issueErr
+		}
+	}
+	pushErr := lc.pushToSynology(ctx, list)
+	if pushErr != nil {
+		slog.Error("failed push to Synology", "error", pushErr)
+	}
+	lc.status.cycleDone()
+	if !lc.DryRun {
+		for i := range list {
+			if list[i].Action != actionKeep {
+				lc.runDeployHook(ctx, lc.store.Location(certKey(list[i].Group)), &list[i])
+			}
+		}
 	}
+	return errors.Join(issueErr, pushErr)
+}
 
-	config := lego.NewConfig(user)
+const (
+	actionKeep  = "keep"
+	actionIssue = "issue"
+	actionRenew = "renew"
+)
+
+// plannedCert is decision what to do with certificate group.
+type plannedCert struct {
+	Group    CertGroup
+	Cached   *certificate.Resource // nil if not in cache
+	Action   string                // one of actionKeep, actionIssue, actionRenew
+	Reason   string
+	NotAfter time.Time // expiration of cached certificate
+	RenewAt  time.Time // planned renewal time of cached certificate
+}
 
-	client, err := lego.NewClient(config)
+// plan decides if certificate should be issued, renewed or kept as is.
+//
+// Certificate is renewed at the earliest of: --renew-before (shifted by jitter) before expiration or
+// the point inside renewal window suggested by CA (ARI). Certificate revoked by CA (OCSP) is re-issued immediately.
+// ARI and OCSP are not checked in dry-run mode (lego client is nil).
+func (lc *CertsAuto) plan(ctx context.Context, group CertGroup, lgc *lego.Client) (*plannedCert, error) {
+	cert, err := loadCert(ctx, lc.store, certKey(group))
+	if errors.Is(err, os.ErrNotExist) {
+		// cached before domains hash was added to key; domains are checked below
+		cert, err = loadCert(ctx, lc.store, legacyCertKey(group.Name))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return &plannedCert{Group: group, Action: actionIssue, Reason: "no certificate"}, nil
+	}
 	if err != nil {
+		// something happen during load
 		return nil, err
 	}
-	reg, err := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	crt, err := parseCert(cert)
 	if err != nil {
+		// parsing failed
 		return nil, err
 	}
+	renewAt, suggested := lc.renewTime(ctx, crt, lgc)
+
+	p := &plannedCert{Group: group, Cached: cert, Action: actionKeep, NotAfter: crt.NotAfter, RenewAt: renewAt}
+	switch {
+	case time.Now().After(crt.NotAfter):
+		p.Action, p.Reason = actionIssue, "the old one expired"
+	case !group.same(crt):
+		p.Action, p.Reason = actionIssue, "domains changed"
+	case lgc != nil && !lc.NoOCSP && isRevoked(lgc, cert):
+		p.Action, p.Reason = actionIssue, "revoked by CA"
+	case !time.Now().Before(renewAt) && suggested:
+		p.Action, p.Reason = actionRenew, "CA suggested renewal window"
+	case !time.Now().Before(renewAt):
+		p.Action, p.Reason = actionRenew, "soon expires"
+	}
+	return p, nil
+}
 
-	user.Registration = reg
+// renewTime is the earliest of: --renew-before (shifted by jitter) before expiration or the point inside renewal
+// window suggested by CA. Flag is true if time suggested by CA.
+func (lc *CertsAuto) renewTime(ctx context.Context, crt *x509.Certificate, lgc *lego.Client) (time.Time, bool) {
+	fraction := jitterFraction(crt)
+	renewAt := crt.NotAfter.Add(-lc.RenewBefore - time.Duration(fraction*float64(lc.RenewJitter)))
+	if window := lc.renewalWindow(ctx, crt, lgc); window != nil {
+		if at := window.At(fraction); at.Before(renewAt) {
+			return at, true
+		}
+	}
+	return renewAt, false
+}
 
-	return client, user.Save(accountFile)
+// renewalWindow returns renewal window suggested by CA or nil if not available. Errors are only logged,
+// so unavailable ARI does not block regular renewal.
+func (lc *CertsAuto) renewalWindow(ctx context.Context, crt *x509.Certificate, lgc *lego.Client) *renewalWindow {
+	if lgc == nil || lc.ari == nil {
+		return nil
+	}
+	window, err := lc.ari.Window(ctx, crt)
+	if err != nil {
+		slog.Warn("failed get renewal info from CA", "domain", crt.Subject.CommonName, "error", err)
+		return nil
+	}
+	return window
 }
 
-type legoAccount struct {
-	Email        string
-	Registration *registration.Resource
-	Key          *ecdsa.PrivateKey `json:"-"`
+// issueOrRenewCerts checks all certificate groups. Failed group does not stop others: certificates of succeeded
+// groups are returned together with joined errors of failed groups.
+func (lc *CertsAuto) issueOrRenewCerts(ctx context.Context, lgc *lego.Client) ([]managedCert, error) {
+	var certs []managedCert
+	var errs []error
+	var preHookDone bool
+	defer func() {
+		if preHookDone {
+			lc.runHook(ctx, hookPost, lc.PostHook, nil)
+		}
+	}()
+	for _, group := range lc.groups() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lc.status.attempt(group.Name)
+		p, err := lc.plan(ctx, group, lgc)
+		if err != nil {
+			lc.fail(ctx, group, err)
+			errs = append(errs, fmt.Errorf("check certificate %s: %w", group.Name, err))
+			continue
+		}
+		cert := p.Cached
+		if !lc.DryRun && !preHookDone && p.Action != actionKeep {
+			lc.runHook(ctx, hookPre, lc.PreHook, nil)
+			preHookDone = true
+		}
+		switch {
+		case lc.DryRun:
+			slog.Info("dry run: certificate planned", "name", group.Name, "domains", group.Domains, "action", p.Action, "reason", p.Reason, "not_after", p.NotAfter, "renew_at", p.RenewAt)
+		case p.Action == actionKeep:
+			slog.Info("certificate is up to date", "name", group.Name, "not_after", p.NotAfter, "renew_at", p.RenewAt)
+			lc.checkExpiring(ctx, p)
+		case p.Action == actionIssue:
+			slog.Info("issuing new certificate", "name", group.Name, "domains", group.Domains, "reason", p.Reason)
+			cert, err = lc.issueCert(ctx, group, lgc)
+			if err != nil {
+				lc.fail(ctx, group, err)
+				lc.checkExpiring(ctx, p)
+				errs = append(errs, fmt.Errorf("issue new certificate for %s: %w", group.Name, err))
+				continue
+			}
+		case p.Action == actionRenew:
+			slog.Info("renewing certificate", "name", group.Name, "domains", group.Domains, "reason", p.Reason)
+			cert, err = lc.renewCert(ctx, group, cert, lgc)
+			if err != nil {
+				lc.fail(ctx, group, err)
+				lc.checkExpiring(ctx, p)
+				errs = append(errs, fmt.Errorf("renew certificate for %s: %w", group.Name, err))
+				continue
+			}
+		}
+		if !lc.DryRun {
+			lc.recordSuccess(ctx, p, cert, lgc)
+		}
+		if cert != nil || lc.DryRun {
+			certs = append(certs, managedCert{Group: group, Resource: cert, Action: p.Action})
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return certs, errors.Join(errs...)
 }
 
-func (la *legoAccount) GetEmail() string {
-	return la.Email
+// recordSuccess updates status of certificate after it was checked, issued or renewed.
+func (lc *CertsAuto) recordSuccess(ctx context.Context, p *plannedCert, cert *certificate.Resource, lgc *lego.Client) {
+	notAfter, renewAt := p.NotAfter, p.RenewAt
+	if p.Action != actionKeep {
+		crt, err := parseCert(cert)
+		if err != nil {
+			lc.fail(ctx, p.Group, err)
+			return
+		}
+		notAfter = crt.NotAfter
+		renewAt, _ = lc.renewTime(ctx, crt, lgc)
+		event := eventIssued
+		if p.Action == actionRenew {
+			event = eventRenewed
+		}
+		lc.notify(ctx, certEvent{Event: event, Name: p.Group.Name, Domains: p.Group.Domains, NotAfter: &notAfter})
+	}
+	lc.status.success(p.Group.Name, p.Action, notAfter, renewAt)
 }
 
-func (la *legoAccount) GetRegistration() *registration.Resource {
-	return la.Registration
+// fail records failure in status and sends notification.
+func (lc *CertsAuto) fail(ctx context.Context, group CertGroup, err error) {
+	lc.status.failed(group.Name, err)
+	lc.notify(ctx, certEvent{Event: eventFailed, Name: group.Name, Domains: group.Domains, Error: err.Error()})
 }
 
-func (la *legoAccount) GetPrivateKey() crypto.PrivateKey {
-	return la.Key
+// checkExpiring sends notification if cached certificate expires soon and was not renewed.
+func (lc *CertsAuto) checkExpiring(ctx context.Context, p *plannedCert) {
+	if p.Cached == nil || time.Until(p.NotAfter) > lc.ExpireBefore {
+		return
+	}
+	notAfter := p.NotAfter
+	lc.notify(ctx, certEvent{Event: eventExpiring, Name: p.Group.Name, Domains: p.Group.Domains, NotAfter: &notAfter})
 }
 
-func (la *legoAccount) MarshalJSON() ([]byte, error) {
-	data, err := x509.MarshalECPrivateKey(la.Key)
-	if err != nil {
-		return nil, err
+func (lc *CertsAuto) pushToSynology(ctx context.Context, certs []managedCert) error {
+	if lc.DryRun {
+		for _, mc := range certs {
+			slog.Info("dry run: certificate would be pushed to Synology", "name", mc.Group.Name)
+		}
+		return nil
 	}
 
-	return json.Marshal(serializedLegoAccount{
-		Email:        la.Email,
-		Registration: la.Registration,
-		RawKey:       data,
-	})
-}
+	syno := lc.SynoClient.Client()
 
-func (la *legoAccount) UnmarshalJSON(bytes []byte) error {
-	var acc serializedLegoAccount
-	err := json.Unmarshal(bytes, &acc)
+	knownCerts, err := syno.ListCerts(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("list certificates in Synology: %w", err)
 	}
 
-	key, err := x509.ParseECPrivateKey(acc.RawKey)
-	if err != nil {
-		return err
+	for i := range certs {
+		mc := &certs[i]
+		res := mc.Resource
+		reason := lc.pushReason(knownCerts, *mc)
+		if reason == "" {
+			slog.Info("certificate in Synology is up to date", "name", mc.Group.Name)
+			continue
+		}
+		slog.Info("pushing certs to Synology", "name", mc.Group.Name, "reason", reason)
+		status, err := syno.UploadCert(ctx, client.NewCertificate{
+			Name: mc.Group.Name,
+			Cert: bytes.NewReader(res.Certificate),
+			CA:   bytes.NewReader(res.IssuerCertificate),
+			Key:  bytes.NewReader(res.PrivateKey),
+		})
+		if err != nil {
+			lc.fail(ctx, mc.Group, err)
+			return fmt.Errorf("push to synology for %s: %w", mc.Group.Name, err)
+		}
+		lc.status.pushed(mc.Group.Name, status.CertificateID)
+		lc.notify(ctx, certEvent{Event: eventPushed, Name: mc.Group.Name, Domains: mc.Group.Domains, CertificateID: status.CertificateID})
+		slog.Info("certificate uploaded", "certificate_id", status.CertificateID, "server_restarted", status.ServerRestarted)
+		mc.CertificateID = status.CertificateID
+		mc.ServerRestarted = status.ServerRestarted
 	}
-	la.Email = acc.Email
-	la.Key = key
-	la.Registration = acc.Registration
-	return nil
-}
 
-func (la *legoAccount) Save(file string) error {
-	return atomicJSON(file, la)
+	return nil
 }
 
-func loadAccount(file string) (*legoAccount, error) {
-	f, err := os.Open(file)
+// pushReason explains why certificate should be pushed to Synology. Empty result means no push needed.
+func (lc *CertsAuto) pushReason(knownCerts []client.Certificate, mc managedCert) string {
+	if lc.ForcePush {
+		return "forced"
+	}
+	found := client.FindCertsByName(knownCerts, mc.Group.Name)
+	if len(found) == 0 {
+		return "missing"
+	}
+	if len(found) > 1 {
+		return "ambiguous" // upload will fail with explanation
+	}
+	if found[0].IsBroken {
+		return "broken"
+	}
+	crt, err := parseCert(mc.Resource)
 	if err != nil {
-		return nil, err
+		return "invalid cached certificate"
 	}
-	defer f.Close()
-	var acc legoAccount
-
-	return &acc, json.NewDecoder(f).Decode(&acc)
-}
-
-type serializedLegoAccount struct {
-	Email        string
-	Registration *registration.Resource
-	RawKey       []byte
+	if !found[0].Matches(crt) {
+		return "changed"
+	}
+	return ""
 }
 
-func saveCert(dir string, resource *certificate.Resource) error {
-	return atomicJSON(filepath.Join(dir, resource.Domain+".json"), serializedCertificate{
-		Domain:            resource.Domain,
-		CertURL:           resource.CertURL,
-		CertStableURL:     resource.CertStableURL,
-		PrivateKey:        resource.PrivateKey,
-		Certificate:       resource.Certificate,
-		IssuerCertificate: resource.IssuerCertificate,
-		CSR:               resource.CSR,
+func (lc *CertsAuto) issueCert(ctx context.Context, group CertGroup, lgc *lego.Client) (*certificate.Resource, error) {
+	request, err := lgc.Certificate.Obtain(certificate.ObtainRequest{
+		Domains: group.Domains,
+		Bundle:  true,
 	})
-}
-
-func loadCert(dir, domain string) (*certificate.Resource, error) {
-	f, err := os.Open(filepath.Join(dir, domain+".json"))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("create certificate request for: %w", err)
 	}
-	defer f.Close()
-	var resource serializedCertificate
-	err = json.NewDecoder(f).Decode(&resource)
+	return request, saveCert(ctx, lc.store, certKey(group), request)
+}
+
+func (lc *CertsAuto) renewCert(ctx context.Context, group CertGroup, res *certificate.Resource, lgc *lego.Client) (*certificate.Resource, error) {
+	ng, err := lgc.Certificate.Renew(*res, true, false, "")
 	if err != nil {
 		return nil, err
 	}
-
-	return &certificate.Resource{
-		Domain:            resource.Domain,
-		CertURL:           resource.CertURL,
-		CertStableURL:     resource.CertStableURL,
-		PrivateKey:        resource.PrivateKey,
-		Certificate:       resource.Certificate,
-		IssuerCertificate: resource.IssuerCertificate,
-		CSR:               resource.CSR,
-	}, nil
+	return ng, saveCert(ctx, lc.store, certKey(group), ng)
 }
 
-type serializedCertificate struct {
-	Domain            string `json:"domain"`
-	CertURL           string `json:"certUrl"`
-	CertStableURL     string `json:"certStableUrl"`
-	PrivateKey        []byte `json:"privateKey"`
-	Certificate       []byte `json:"certificate"`
-	IssuerCertificate []byte `json:"issuer_certificate"`
-	CSR               []byte `json:"csr"`
+func (lc *CertsAuto) setupChallenge(lgc *lego.Client, provider challenge.Provider) error {
+	switch lc.Challenge {
+	case challengeHTTP01:
+		return lgc.Challenge.SetHTTP01Provider(provider)
+	case challengeTLSALPN01:
+		return lgc.Challenge.SetTLSALPN01Provider(provider)
+	default:
+		var opts = []dns01.ChallengeOption{
+			dns01.AddDNSTimeout(lc.Timeout),
+		}
+		if len(lc.DNS) > 0 {
+			opts = append(opts, dns01.AddRecursiveNameservers(lc.DNS))
+		}
+		return lgc.Challenge.SetDNS01Provider(provider, opts...)
+	}
 }
 
-func atomicJSON(file string, data interface{}) error {
-	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
-		return err
-	}
-	tempFile := file + ".tmp"
-	f, err := os.Create(tempFile)
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tempFile)
-	defer f.Close()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	err = enc.Encode(data)
-	if err != nil {
-		return err
-	}
-	err = f.Close()
-	if err != nil {
-		return err
+// challengeProvider validates challenge options and creates provider (solver) for selected challenge.
+func (lc *CertsAuto) challengeProvider() (challenge.Provider, error) {
+	switch lc.Challenge {
+	case challengeHTTP01:
+		if lc.Webroot != "" {
+			return webroot.NewHTTPProvider(lc.Webroot)
+		}
+		host, port, err := net.SplitHostPort(lc.HTTPListen)
+		if err != nil {
+			return nil, fmt.Errorf("parse HTTP listen address: %w", err)
+		}
+		return http01.NewProviderServer(host, port), nil
+	case challengeTLSALPN01:
+		host, port, err := net.SplitHostPort(lc.TLSListen)
+		if err != nil {
+			return nil, fmt.Errorf("parse TLS listen address: %w", err)
+		}
+		return tlsalpn01.NewProviderServer(host, port), nil
+	default:
+		if lc.Provider == "" {
+			return nil, fmt.Errorf("DNS provider should be set for %s challenge", challengeDNS01) //nolint:goerr113
+		}
+		return dns.NewDNSChallengeProviderByName(lc.Provider)
 	}
-	return os.Rename(tempFile, file)
 }
 
-func parseCert(cert *certificate.Resource) (*x509.Certificate, error) {
-	info, _ := pem.Decode(cert.Certificate)
-	return x509.ParseCertificate(info.Bytes)
+// checkWildcards returns error if wildcard domains requested with challenge which doesn't support them.
+func (lc *CertsAuto) checkWildcards() error {
+	if lc.Challenge == challengeDNS01 {
+		return nil
+	}
+	for _, group := range lc.groups() {
+		for _, domain := range group.Domains {
+			if strings.HasPrefix(domain, "*.") {
+				return fmt.Errorf("wildcard domain %s requires %s challenge", domain, challengeDNS01) //nolint:goerr113
+			}
+		}
+	}
+	return nil
 }
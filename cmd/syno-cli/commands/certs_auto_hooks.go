@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"encoding/pem"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	hookPre    = "pre"
+	hookPost   = "post"
+	hookDeploy = "deploy"
+)
+
+// Hooks are shell commands executed around certificates issuance.
+//
+// Pre-hook runs once per cycle before the first certificate is issued or renewed, post-hook runs after all
+// certificates processed (only if pre-hook was triggered), deploy-hook runs for each issued or renewed certificate
+// after push to Synology.
+//
+//nolint:staticcheck
+type Hooks struct {
+	PreHook     string        `long:"pre-hook" env:"PRE_HOOK" description:"Shell command executed before issuing or renewing certificates"`
+	PostHook    string        `long:"post-hook" env:"POST_HOOK" description:"Shell command executed after issuing or renewing certificates"`
+	DeployHook  string        `long:"deploy-hook" env:"DEPLOY_HOOK" description:"Shell command executed for each issued or renewed certificate after push to Synology"`
+	HookTimeout time.Duration `long:"hook-timeout" env:"HOOK_TIMEOUT" description:"Maximum execution time for each hook" default:"5m"`
+	failures    atomic.Int64
+}
+
+// HookFailures returns number of failed hooks since start. Reported by /status and makes --once exit code non-zero.
+func (h *Hooks) HookFailures() int64 {
+	return h.failures.Load()
+}
+
+// runHook executes hook command if defined. Failures are logged and counted, but not returned.
+func (h *Hooks) runHook(ctx context.Context, kind string, command string, env []string) {
+	if command == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.HookTimeout)
+	defer cancel()
+
+	slog.Info("running hook", "hook", kind)
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "SYNO_HOOK="+kind)
+	cmd.Env = append(cmd.Env, env...)
+	if err := cmd.Run(); err != nil {
+		slog.Error("hook failed", "hook", kind, "error", err, "failures", h.failures.Add(1))
+	}
+}
+
+// runDeployHook materializes certificate as PEM files in temporary directory and executes deploy hook.
+// Files are removed after hook finished.
+func (h *Hooks) runDeployHook(ctx context.Context, cacheLocation string, mc *managedCert) {
+	if h.DeployHook == "" {
+		return
+	}
+	tmpDir, err := os.MkdirTemp("", "syno-cert-")
+	if err != nil {
+		slog.Error("failed create temporary directory for hook", "hook", hookDeploy, "error", err, "failures", h.failures.Add(1))
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	res := mc.Resource
+	leaf := res.Certificate // issued as bundle, so the first block is leaf
+	if block, _ := pem.Decode(res.Certificate); block != nil {
+		leaf = pem.EncodeToMemory(block)
+	}
+	files := map[string][]byte{
+		"key.pem":       res.PrivateKey,
+		"cert.pem":      leaf,
+		"chain.pem":     res.IssuerCertificate,
+		"fullchain.pem": append(append([]byte{}, leaf...), res.IssuerCertificate...),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0600); err != nil {
+			slog.Error("failed write certificate for hook", "hook", hookDeploy, "file", name, "error", err, "failures", h.failures.Add(1))
+			return
+		}
+	}
+
+	env := []string{
+		"SYNO_CERT_NAME=" + mc.Group.Name,
+		"SYNO_CERT_DOMAIN=" + mc.Group.Domains[0],
+		"SYNO_CERT_DOMAINS=" + strings.Join(mc.Group.Domains, ","),
+		"SYNO_CERT_ACTION=" + mc.Action,
+		"SYNO_CERT_CACHE_FILE=" + cacheLocation,
+		"SYNO_CERT_KEY_FILE=" + filepath.Join(tmpDir, "key.pem"),
+		"SYNO_CERT_FILE=" + filepath.Join(tmpDir, "cert.pem"),
+		"SYNO_CERT_CHAIN_FILE=" + filepath.Join(tmpDir, "chain.pem"),
+		"SYNO_CERT_FULLCHAIN_FILE=" + filepath.Join(tmpDir, "fullchain.pem"),
+		"SYNO_CERT_ID=" + mc.CertificateID,
+		"SYNO_SERVER_RESTARTED=" + strconv.FormatBool(mc.ServerRestarted),
+	}
+	h.runHook(ctx, hookDeploy, h.DeployHook, env)
+}
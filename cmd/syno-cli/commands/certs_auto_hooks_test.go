@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks_failures(t *testing.T) {
+	ctx := context.Background()
+	hooks := &Hooks{HookTimeout: time.Minute}
+	status := newAutoStatus([]CertGroup{{Name: "example", Domains: []string{"example.com"}}}, hooks.HookFailures)
+
+	hooks.runHook(ctx, hookPre, "", nil)
+	hooks.runHook(ctx, hookPre, "true", nil)
+	assert.Zero(t, hooks.HookFailures())
+	assert.Zero(t, status.report().HookFailures)
+
+	hooks.runHook(ctx, hookPre, "exit 1", nil)
+	hooks.runHook(ctx, hookPost, "exit 2", nil)
+	assert.Equal(t, int64(2), hooks.HookFailures())
+	assert.Equal(t, int64(2), status.report().HookFailures)
+	assert.True(t, status.report().Healthy, "hooks do not affect certificates health")
+
+	hooks.HookTimeout = 10 * time.Millisecond
+	hooks.runHook(ctx, hookPost, "sleep 10", nil)
+	assert.Equal(t, int64(3), hooks.HookFailures())
+}
+
+func TestHooks_runDeployHook(t *testing.T) {
+	certPEM, keyPEM := testCertPEM(t, time.Now().Add(time.Hour), "example.com", "www.example.com")
+	issuerPEM, _ := testCertPEM(t, time.Now().Add(time.Hour), "issuer")
+	output := filepath.Join(t.TempDir(), "output")
+
+	hooks := &Hooks{
+		HookTimeout: time.Minute,
+		DeployHook: `{
+			echo "$SYNO_HOOK $SYNO_CERT_NAME $SYNO_CERT_DOMAIN $SYNO_CERT_DOMAINS $SYNO_CERT_ACTION $SYNO_CERT_CACHE_FILE $SYNO_CERT_ID $SYNO_SERVER_RESTARTED"
+			cat "$SYNO_CERT_KEY_FILE" "$SYNO_CERT_FILE" "$SYNO_CERT_CHAIN_FILE" "$SYNO_CERT_FULLCHAIN_FILE"
+			dirname "$SYNO_CERT_FILE"
+		} > "` + output + `"`,
+	}
+	hooks.runDeployHook(context.Background(), "/cache/example.json", &managedCert{
+		Group:           CertGroup{Name: "example", Domains: []string{"example.com", "www.example.com"}},
+		Resource:        &certificate.Resource{PrivateKey: keyPEM, Certificate: append(append([]byte{}, certPEM...), issuerPEM...), IssuerCertificate: issuerPEM},
+		Action:          actionRenew,
+		CertificateID:   "abc",
+		ServerRestarted: true,
+	})
+	require.Zero(t, hooks.HookFailures())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	content := string(data)
+	header, content, _ := strings.Cut(content, "\n")
+	assert.Equal(t, "deploy example example.com example.com,www.example.com renew /cache/example.json abc true", header)
+	expected := string(keyPEM) + string(certPEM) + string(issuerPEM) + string(certPEM) + string(issuerPEM)
+	assert.True(t, strings.HasPrefix(content, expected))
+	tmpDir := strings.TrimSpace(strings.TrimPrefix(content, expected))
+	assert.NoDirExists(t, tmpDir, "temporary files removed")
+}
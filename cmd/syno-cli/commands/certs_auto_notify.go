@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	eventIssued   = "issued"
+	eventRenewed  = "renewed"
+	eventPushed   = "pushed"
+	eventFailed   = "failed"
+	eventExpiring = "expiring"
+
+	defaultNotifyRate = 6 * time.Hour
+	defaultDSMTarget  = "@administrators"
+)
+
+//nolint:gochecknoglobals
+var knownEvents = []string{eventIssued, eventRenewed, eventPushed, eventFailed, eventExpiring}
+
+// Notifications send certificate events to configured sinks.
+//
+// Each sink is URL with optional query parameters `events` (comma separated list of events, default all) and
+// `rate` (minimal interval between notifications of the same event for the same certificate, default 6h, 0 disables):
+//
+//   - http:// or https:// - POST event as JSON;
+//   - smtp:// (STARTTLS if supported) or smtps:// (implicit TLS) - email, parameters `from` and `to` (comma separated);
+//   - dsm://[target] - DSM notification sent by Synology client, target is user or @group (default @administrators).
+//
+//nolint:staticcheck
+type Notifications struct {
+	Notify        []string      `long:"notify" env:"NOTIFY" env-delim:" " description:"Notification sink URL: http(s)://, smtp(s):// or dsm://, with optional events and rate parameters. Can be repeated"`
+	ExpireBefore  time.Duration `long:"notify-expire-before" env:"NOTIFY_EXPIRE_BEFORE" description:"Send expiring event if certificate expires sooner and was not renewed" default:"168h"`
+	NotifyTimeout time.Duration `long:"notify-timeout" env:"NOTIFY_TIMEOUT" description:"Maximum time to deliver single notification" default:"30s"`
+	sinks         []*notifySink
+}
+
+// certEvent is notification about certificate.
+type certEvent struct {
+	Event         string     `json:"event"`
+	Name          string     `json:"name"`
+	Domains       []string   `json:"domains"`
+	Time          time.Time  `json:"time"`
+	NotAfter      *time.Time `json:"not_after,omitempty"`
+	CertificateID string     `json:"certificate_id,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+func (ce *certEvent) Subject() string {
+	return fmt.Sprintf("[syno-cli] certificate %s %s", ce.Name, ce.Event)
+}
+
+func (ce *certEvent) Text() string {
+	var buf strings.Builder
+	_, _ = fmt.Fprintf(&buf, "Certificate: %s\n", ce.Name)
+	_, _ = fmt.Fprintf(&buf, "Domains: %s\n", strings.Join(ce.Domains, ", "))
+	_, _ = fmt.Fprintf(&buf, "Event: %s\n", ce.Event)
+	if ce.NotAfter != nil {
+		_, _ = fmt.Fprintf(&buf, "Expires: %s\n", ce.NotAfter.Format(time.RFC1123))
+	}
+	if ce.CertificateID != "" {
+		_, _ = fmt.Fprintf(&buf, "Synology certificate ID: %s\n", ce.CertificateID)
+	}
+	if ce.Error != "" {
+		_, _ = fmt.Fprintf(&buf, "Error: %s\n", ce.Error)
+	}
+	return buf.String()
+}
+
+// setupNotifications parses sinks. DSM notifications are sent by Synology client.
+func (ns *Notifications) setupNotifications(syno func() *client.Client) error {
+	for _, spec := range ns.Notify {
+		sink, err := parseSink(spec, syno)
+		if err != nil {
+			return fmt.Errorf("parse notification sink: %w", err)
+		}
+		ns.sinks = append(ns.sinks, sink)
+	}
+	return nil
+}
+
+// notify sends event to all matched sinks. Delivery errors are only logged.
+func (ns *Notifications) notify(ctx context.Context, event certEvent) {
+	event.Time = time.Now()
+	for _, sink := range ns.sinks {
+		if !sink.accept(event) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, ns.NotifyTimeout)
+		err := sink.send(ctx, event)
+		cancel()
+		if err != nil {
+			slog.Error("failed send notification", "sink", sink.name, "event", event.Event, "name", event.Name, "error", err)
+			continue
+		}
+		sink.delivered(event)
+	}
+}
+
+type notifySink struct {
+	name   string
+	events map[string]bool // nil means all
+	rate   time.Duration
+	send   func(ctx context.Context, event certEvent) error
+
+	lock sync.Mutex
+	last map[string]time.Time // event/name -> last notification
+}
+
+// accept checks filter and rate limit. Only delivered events are counted by rate limiter.
+func (sink *notifySink) accept(event certEvent) bool {
+	if sink.events != nil && !sink.events[event.Event] {
+		return false
+	}
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	key := event.Event + "/" + event.Name
+	if last, ok := sink.last[key]; ok && event.Time.Sub(last) < sink.rate {
+		slog.Debug("notification suppressed by rate limit", "sink", sink.name, "event", event.Event, "name", event.Name)
+		return false
+	}
+	return true
+}
+
+// delivered counts event by rate limiter.
+func (sink *notifySink) delivered(event certEvent) {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	sink.last[event.Event+"/"+event.Name] = event.Time
+}
+
+func parseSink(spec string, syno func() *client.Client) (*notifySink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	sink := &notifySink{name: u.Scheme + "://" + u.Host, rate: defaultNotifyRate, last: make(map[string]time.Time)}
+	if events := query.Get("events"); events != "" {
+		sink.events = make(map[string]bool)
+		for _, event := range strings.Split(events, ",") {
+			event = strings.TrimSpace(event)
+			if !isKnownEvent(event) {
+				return nil, fmt.Errorf("unknown event %q, supported: %s", event, strings.Join(knownEvents, ", ")) //nolint:goerr113
+			}
+			sink.events[event] = true
+		}
+	}
+	if rate := query.Get("rate"); rate != "" {
+		sink.rate, err = time.ParseDuration(rate)
+		if err != nil {
+			return nil, fmt.Errorf("parse rate: %w", err)
+		}
+	}
+	query.Del("events")
+	query.Del("rate")
+
+	switch u.Scheme {
+	case "http", "https":
+		u.RawQuery = query.Encode()
+		sink.send = webhookSender(u.String())
+	case "smtp", "smtps":
+		from, to := query.Get("from"), query.Get("to")
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("from and to parameters required for %s sink", u.Scheme) //nolint:goerr113
+		}
+		sink.send = smtpSender(u, from, strings.Split(to, ","))
+	case "dsm":
+		target := u.Host
+		if target == "" {
+			target = defaultDSMTarget
+		}
+		sink.send = dsmSender(syno, target)
+	default:
+		return nil, fmt.Errorf("unsupported sink %q", u.Scheme) //nolint:goerr113
+	}
+	return sink, nil
+}
+
+func isKnownEvent(event string) bool {
+	for _, known := range knownEvents {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+func webhookSender(endpoint string) func(ctx context.Context, event certEvent) error {
+	return func(ctx context.Context, event certEvent) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode/100 != 2 { //nolint:gomnd
+			return fmt.Errorf("unexpected status %d", res.StatusCode) //nolint:goerr113
+		}
+		return nil
+	}
+}
+
+func smtpSender(server *url.URL, from string, to []string) func(ctx context.Context, event certEvent) error {
+	return func(ctx context.Context, event certEvent) error {
+		host, port := server.Hostname(), server.Port()
+		if port == "" && server.Scheme == "smtps" {
+			port = "465"
+		} else if port == "" {
+			port = "25"
+		}
+
+		var conn net.Conn
+		var err error
+		if server.Scheme == "smtps" {
+			dialer := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+			conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		} else {
+			var dialer net.Dialer
+			conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		}
+		if err != nil {
+			return fmt.Errorf("connect: %w", err)
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		cl, err := smtp.NewClient(conn, host)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("handshake: %w", err)
+		}
+		defer cl.Close()
+
+		if ok, _ := cl.Extension("STARTTLS"); ok && server.Scheme == "smtp" {
+			if err := cl.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		}
+		if server.User != nil {
+			password, _ := server.User.Password()
+			if err := cl.Auth(smtp.PlainAuth("", server.User.Username(), password, host)); err != nil {
+				return fmt.Errorf("auth: %w", err)
+			}
+		}
+		if err := cl.Mail(from); err != nil {
+			return fmt.Errorf("mail from: %w", err)
+		}
+		for _, rcpt := range to {
+			if err := cl.Rcpt(strings.TrimSpace(rcpt)); err != nil {
+				return fmt.Errorf("rcpt to %s: %w", rcpt, err)
+			}
+		}
+		w, err := cl.Data()
+		if err != nil {
+			return fmt.Errorf("data: %w", err)
+		}
+		_, _ = fmt.Fprintf(w, "From: %s\r\n", from)
+		_, _ = fmt.Fprintf(w, "To: %s\r\n", strings.Join(to, ", "))
+		_, _ = fmt.Fprintf(w, "Subject: %s\r\n", event.Subject())
+		_, _ = fmt.Fprintf(w, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+		_, _ = fmt.Fprintf(w, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		_, _ = fmt.Fprint(w, strings.ReplaceAll(event.Text(), "\n", "\r\n"))
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("send message: %w", err)
+		}
+		return cl.Quit()
+	}
+}
+
+// dsmSender shows notification in DSM notification center using DSM Web API.
+func dsmSender(syno func() *client.Client, target string) func(ctx context.Context, event certEvent) error {
+	return func(ctx context.Context, event certEvent) error {
+		return syno().Notify(ctx, client.Notification{
+			Title:      event.Subject(),
+			Message:    event.Text(),
+			Recipients: []string{target},
+		})
+	}
+}
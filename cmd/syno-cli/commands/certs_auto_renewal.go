@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	ariDefaultRetry = 6 * time.Hour
+	ariMinRetry     = time.Minute
+	ariMaxRetry     = 24 * time.Hour
+	ariMaxBody      = 1024 * 1024
+)
+
+// renewalWindow is suggested by CA period when certificate should be renewed.
+type renewalWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// At picks point inside window. The same fraction gives the same point.
+func (rw renewalWindow) At(fraction float64) time.Time {
+	return rw.Start.Add(time.Duration(fraction * float64(rw.End.Sub(rw.Start))))
+}
+
+// clamp limits window by certificate validity period.
+func (rw renewalWindow) clamp(notBefore, notAfter time.Time) renewalWindow {
+	limit := func(t time.Time) time.Time {
+		switch {
+		case t.Before(notBefore):
+			return notBefore
+		case t.After(notAfter):
+			return notAfter
+		default:
+			return t
+		}
+	}
+	return renewalWindow{Start: limit(rw.Start), End: limit(rw.End)}
+}
+
+// ariClient fetches ACME Renewal Information (RFC 9773). Responses are cached till Retry-After.
+type ariClient struct {
+	http      *http.Client
+	directory string
+
+	lock     sync.Mutex
+	endpoint *string // nil if directory not fetched yet, empty if ARI not supported
+	cache    map[string]ariCached
+}
+
+type ariCached struct {
+	window  *renewalWindow
+	expires time.Time
+}
+
+func newARIClient(config *lego.Config) *ariClient {
+	return &ariClient{
+		http:      config.HTTPClient,
+		directory: config.CADirURL,
+		cache:     make(map[string]ariCached),
+	}
+}
+
+// Window returns suggested renewal window or nil if CA does not support ARI.
+func (ac *ariClient) Window(ctx context.Context, crt *x509.Certificate) (*renewalWindow, error) {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+
+	certID, err := ariCertID(crt)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := ac.cache[certID]; ok && time.Now().Before(cached.expires) {
+		return cached.window, nil
+	}
+
+	endpoint, err := ac.renewalInfoURL(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get ACME directory: %w", err)
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	var info struct {
+		SuggestedWindow renewalWindow `json:"suggestedWindow"`
+		ExplanationURL  string        `json:"explanationURL"`
+	}
+	res, err := ac.get(ctx, strings.TrimSuffix(endpoint, "/")+"/"+certID, &info)
+	if err != nil {
+		return nil, fmt.Errorf("get renewal info: %w", err)
+	}
+	window := info.SuggestedWindow
+	if window.End.Before(window.Start) {
+		return nil, fmt.Errorf("invalid renewal window %v - %v", window.Start, window.End) //nolint:goerr113
+	}
+	window = window.clamp(crt.NotBefore, crt.NotAfter)
+	if info.ExplanationURL != "" {
+		slog.Info("CA provided renewal explanation", "domain", crt.Subject.CommonName, "url", info.ExplanationURL)
+	}
+	ac.cache[certID] = ariCached{window: &window, expires: time.Now().Add(retryAfter(res))}
+	return &window, nil
+}
+
+func (ac *ariClient) renewalInfoURL(ctx context.Context) (string, error) {
+	if ac.endpoint != nil {
+		return *ac.endpoint, nil
+	}
+	var dir struct {
+		RenewalInfo string `json:"renewalInfo"`
+	}
+	if _, err := ac.get(ctx, ac.directory, &dir); err != nil {
+		return "", err
+	}
+	ac.endpoint = &dir.RenewalInfo
+	return dir.RenewalInfo, nil
+}
+
+func (ac *ariClient) get(ctx context.Context, url string, out interface{}) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ac.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode) //nolint:goerr113
+	}
+	return res, json.NewDecoder(io.LimitReader(res.Body, ariMaxBody)).Decode(out)
+}
+
+// ariCertID builds certificate identifier: base64url(authority key id) "." base64url(DER serial).
+func ariCertID(crt *x509.Certificate) (string, error) {
+	if len(crt.AuthorityKeyId) == 0 {
+		return "", fmt.Errorf("certificate has no authority key identifier") //nolint:goerr113
+	}
+	serial := crt.SerialNumber.Bytes()
+	if len(serial) == 0 || serial[0]&0x80 != 0 {
+		// DER integer is signed, positive numbers with high bit set are prefixed by zero
+		serial = append([]byte{0}, serial...)
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(crt.AuthorityKeyId) + "." + enc.EncodeToString(serial), nil
+}
+
+// retryAfter is time till next renewal info request: Retry-After (seconds or HTTP date) limited by
+// ariMinRetry and ariMaxRetry, or ariDefaultRetry if header is missing or invalid.
+func retryAfter(res *http.Response) time.Duration {
+	value := res.Header.Get("Retry-After")
+	delay := ariDefaultRetry
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = time.Until(at)
+	}
+	return min(max(delay, ariMinRetry), ariMaxRetry)
+}
+
+// isRevoked checks certificate status by OCSP. Errors (including missing OCSP server, which is normal for
+// CAs dropped OCSP) are reported as not revoked.
+func isRevoked(lgc *lego.Client, res *certificate.Resource) bool {
+	bundle := append(append([]byte{}, res.Certificate...), res.IssuerCertificate...)
+	_, status, err := lgc.Certificate.GetOCSP(bundle)
+	if err != nil {
+		slog.Debug("OCSP check skipped", "domain", res.Domain, "error", err)
+		return false
+	}
+	return status.Status == ocsp.Revoked
+}
+
+// jitterFraction is stable pseudo-random number in [0, 1) for certificate on this host, so each host renews
+// at own time, but the time does not change between cycles.
+func jitterFraction(crt *x509.Certificate) float64 {
+	hostname, _ := os.Hostname()
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(hostname))
+	_, _ = h.Write(crt.SerialNumber.Bytes())
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestARICertID(t *testing.T) {
+	// example from RFC 9773, section 4.1
+	aki := []byte{0x69, 0x88, 0x5B, 0x6B, 0x87, 0x46, 0x40, 0x41, 0xE1, 0xB3, 0x7B, 0x84, 0x7B, 0xA0, 0xAE, 0x2C, 0xDE, 0x01, 0xC8, 0xD4}
+
+	tests := []struct {
+		name   string
+		aki    []byte
+		serial int64
+		id     string
+		err    bool
+	}{
+		{name: "rfc 9773", aki: aki, serial: 0x87654321, id: "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"},
+		{name: "serial without high bit", aki: aki, serial: 0x7F01, id: "aYhba4dGQEHhs3uEe6CuLN4ByNQ.fwE"},
+		{name: "zero serial", aki: aki, serial: 0, id: "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AA"},
+		{name: "no authority key id", serial: 0x87654321, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ariCertID(&x509.Certificate{AuthorityKeyId: tt.aki, SerialNumber: big.NewInt(tt.serial)})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestJitterFraction(t *testing.T) {
+	seen := make(map[float64]bool)
+	for serial := int64(1); serial <= 100; serial++ {
+		crt := &x509.Certificate{SerialNumber: big.NewInt(serial)}
+		fraction := jitterFraction(crt)
+		assert.GreaterOrEqual(t, fraction, 0.0)
+		assert.Less(t, fraction, 1.0)
+		assert.Equal(t, fraction, jitterFraction(crt), "stable for the same certificate")
+		seen[fraction] = true
+	}
+	assert.Len(t, seen, 100, "different for different certificates")
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		delay time.Duration
+	}{
+		{name: "missing", delay: ariDefaultRetry},
+		{name: "invalid", value: "soon", delay: ariDefaultRetry},
+		{name: "seconds", value: "7200", delay: 2 * time.Hour},
+		{name: "seconds below minimum", value: "10", delay: ariMinRetry},
+		{name: "zero", value: "0", delay: ariMinRetry},
+		{name: "negative", value: "-60", delay: ariMinRetry},
+		{name: "seconds above maximum", value: "604800", delay: ariMaxRetry},
+		{name: "date", value: time.Now().Add(3 * time.Hour).UTC().Format(http.TimeFormat), delay: 3 * time.Hour},
+		{name: "date in past", value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), delay: ariMinRetry},
+		{name: "date above maximum", value: time.Now().Add(72 * time.Hour).UTC().Format(http.TimeFormat), delay: ariMaxRetry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				res.Header.Set("Retry-After", tt.value)
+			}
+			assert.InDelta(t, tt.delay, retryAfter(res), float64(2*time.Second))
+		})
+	}
+}
+
+func TestCertsAuto_renewTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	crt := &x509.Certificate{
+		SerialNumber:   big.NewInt(0x87654321),
+		AuthorityKeyId: []byte{1, 2, 3, 4},
+		NotBefore:      now.Add(-60 * 24 * time.Hour),
+		NotAfter:       now.Add(30 * 24 * time.Hour),
+	}
+	const renewBefore = 20 * 24 * time.Hour
+	byFlag := crt.NotAfter.Add(-renewBefore)
+	fraction := jitterFraction(crt)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		jitter    time.Duration
+		noARI     bool
+		status    int            // renewal info response status
+		window    *renewalWindow // nil if CA does not support ARI
+		renewAt   time.Time
+		suggested bool
+	}{
+		{name: "ARI disabled", noARI: true, renewAt: byFlag},
+		{name: "ARI not supported", renewAt: byFlag},
+		{name: "ARI failed", status: http.StatusInternalServerError, window: &renewalWindow{}, renewAt: byFlag},
+		{
+			name:   "jitter",
+			noARI:  true,
+			jitter: day,
+			// shifted earlier by fraction of jitter
+			renewAt: byFlag.Add(-time.Duration(fraction * float64(day))),
+		},
+		{
+			name:      "window before flag",
+			window:    &renewalWindow{Start: byFlag.Add(-10 * day), End: byFlag.Add(-5 * day)},
+			renewAt:   renewalWindow{Start: byFlag.Add(-10 * day), End: byFlag.Add(-5 * day)}.At(fraction),
+			suggested: true,
+		},
+		{
+			name:    "window after flag",
+			window:  &renewalWindow{Start: byFlag.Add(day), End: byFlag.Add(2 * day)},
+			renewAt: byFlag,
+		},
+		{
+			name:    "window after expiration clamped",
+			window:  &renewalWindow{Start: crt.NotAfter.Add(day), End: crt.NotAfter.Add(2 * day)},
+			renewAt: byFlag,
+		},
+		{
+			name:      "window before issue clamped",
+			window:    &renewalWindow{Start: crt.NotBefore.Add(-2 * day), End: crt.NotBefore.Add(-day)},
+			renewAt:   crt.NotBefore,
+			suggested: true,
+		},
+		{
+			name:      "window around issue clamped",
+			window:    &renewalWindow{Start: crt.NotBefore.Add(-day), End: crt.NotBefore.Add(day)},
+			renewAt:   renewalWindow{Start: crt.NotBefore, End: crt.NotBefore.Add(day)}.At(fraction),
+			suggested: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				switch request.URL.Path {
+				case "/directory":
+					var dir = map[string]string{}
+					if tt.window != nil {
+						dir["renewalInfo"] = "http://" + request.Host + "/renewal-info/"
+					}
+					_ = json.NewEncoder(writer).Encode(dir)
+				case "/renewal-info/AQIDBA.AIdlQyE":
+					if tt.status != 0 {
+						writer.WriteHeader(tt.status)
+						return
+					}
+					_ = json.NewEncoder(writer).Encode(map[string]any{"suggestedWindow": tt.window})
+				default:
+					http.NotFound(writer, request)
+				}
+			}))
+			defer srv.Close()
+
+			lc := &CertsAuto{RenewBefore: renewBefore, RenewJitter: tt.jitter}
+			if !tt.noARI {
+				lc.ari = newARIClient(&lego.Config{HTTPClient: srv.Client(), CADirURL: srv.URL + "/directory"})
+			}
+			renewAt, suggested := lc.renewTime(context.Background(), crt, &lego.Client{})
+			assert.Equal(t, tt.suggested, suggested)
+			assert.WithinDuration(t, tt.renewAt, renewAt, time.Millisecond)
+		})
+	}
+}
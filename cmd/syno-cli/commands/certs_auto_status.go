@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const statusReadTimeout = 10 * time.Second
+
+// certStatus is observable state of certificate managed by cert auto.
+type certStatus struct {
+	Name        string     `json:"name"`
+	Domains     []string   `json:"domains"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"` // last time certificate was checked
+	LastSuccess *time.Time `json:"last_success,omitempty"` // last time certificate was checked, issued or renewed without errors
+	LastAction  string     `json:"last_action,omitempty"`  // last action (keep, issue, renew)
+	NotAfter    *time.Time `json:"not_after,omitempty"`    // expiration of current certificate
+	NextRenewal *time.Time `json:"next_renewal,omitempty"` // planned renewal time of current certificate
+	LastError   string     `json:"last_error,omitempty"`   // last error, cleared after success
+	LastPush    *time.Time `json:"last_push,omitempty"`    // last time certificate uploaded to Synology
+	CertID      string     `json:"certificate_id,omitempty"`
+}
+
+// Healthy means certificate is valid and the last attempt did not fail.
+func (cs *certStatus) Healthy() bool {
+	return cs.LastError == "" && (cs.NotAfter == nil || time.Now().Before(*cs.NotAfter))
+}
+
+// autoStatus is thread-safe state of all certificates managed by cert auto.
+type autoStatus struct {
+	lock         sync.RWMutex
+	certs        []*certStatus
+	lastCycle    *time.Time
+	hookFailures func() int64
+}
+
+func newAutoStatus(groups []CertGroup, hookFailures func() int64) *autoStatus {
+	as := &autoStatus{hookFailures: hookFailures}
+	for _, group := range groups {
+		as.certs = append(as.certs, &certStatus{Name: group.Name, Domains: group.Domains})
+	}
+	return as
+}
+
+// update certificate status by name. Unknown names are ignored.
+func (as *autoStatus) update(name string, fn func(cs *certStatus)) {
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	for _, cs := range as.certs {
+		if cs.Name == name {
+			fn(cs)
+			return
+		}
+	}
+}
+
+func (as *autoStatus) attempt(name string) {
+	now := time.Now()
+	as.update(name, func(cs *certStatus) {
+		cs.LastAttempt = &now
+	})
+}
+
+func (as *autoStatus) success(name string, action string, notAfter, nextRenewal time.Time) {
+	now := time.Now()
+	as.update(name, func(cs *certStatus) {
+		cs.LastSuccess = &now
+		cs.LastAction = action
+		cs.LastError = ""
+		cs.NotAfter = &notAfter
+		cs.NextRenewal = &nextRenewal
+	})
+}
+
+func (as *autoStatus) failed(name string, err error) {
+	as.update(name, func(cs *certStatus) {
+		cs.LastError = err.Error()
+	})
+}
+
+func (as *autoStatus) pushed(name string, certID string) {
+	now := time.Now()
+	as.update(name, func(cs *certStatus) {
+		cs.LastPush = &now
+		cs.CertID = certID
+	})
+}
+
+func (as *autoStatus) cycleDone() {
+	now := time.Now()
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	as.lastCycle = &now
+}
+
+type statusReport struct {
+	Healthy      bool         `json:"healthy"`
+	LastCycle    *time.Time   `json:"last_cycle,omitempty"`
+	HookFailures int64        `json:"hook_failures"` // failed hooks since start
+	Certificates []certStatus `json:"certificates"`
+}
+
+func (as *autoStatus) report() statusReport {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	var rep = statusReport{Healthy: true, LastCycle: as.lastCycle, HookFailures: as.hookFailures(), Certificates: make([]certStatus, 0, len(as.certs))}
+	for _, cs := range as.certs {
+		rep.Certificates = append(rep.Certificates, *cs)
+		rep.Healthy = rep.Healthy && cs.Healthy()
+	}
+	return rep
+}
+
+// serve starts status HTTP server in background:
+//
+//   - /healthz - 200 if all certificates are valid and the last attempts succeeded, otherwise 503;
+//   - /status - JSON report of all certificates and number of failed hooks.
+func (as *autoStatus) serve(address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen status server: %w", err)
+	}
+
+	srv := &http.Server{Handler: as.handler(), ReadHeaderTimeout: statusReadTimeout}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("status server stopped", "error", err)
+		}
+	}()
+	slog.Info("status server started", "address", listener.Addr().String())
+	return srv, nil
+}
+
+// handler serves /healthz and /status endpoints.
+func (as *autoStatus) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(writer http.ResponseWriter, _ *http.Request) {
+		rep := as.report()
+		writer.Header().Set("Content-Type", "text/plain")
+		if !rep.Healthy {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			for _, cs := range rep.Certificates {
+				if cs.Healthy() {
+					continue
+				}
+				reason := cs.LastError
+				if reason == "" {
+					reason = "certificate expired"
+				}
+				_, _ = fmt.Fprintf(writer, "%s: %s\n", cs.Name, reason)
+			}
+			return
+		}
+		_, _ = writer.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/status", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(as.report())
+	})
+	return mux
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoStatus_handler(t *testing.T) {
+	groups := []CertGroup{
+		{Name: "web", Domains: []string{"example.com"}},
+		{Name: "mail", Domains: []string{"mail.example.com"}},
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		setup   func(as *autoStatus)
+		healthy bool
+		reasons string
+	}{
+		{name: "not checked yet", setup: func(*autoStatus) {}, healthy: true},
+		{name: "healthy", healthy: true, setup: func(as *autoStatus) {
+			as.success("web", actionKeep, now.Add(time.Hour), now.Add(time.Minute))
+			as.success("mail", actionRenew, now.Add(time.Hour), now.Add(time.Minute))
+			as.cycleDone()
+		}},
+		{name: "failing", reasons: "mail: boom\n", setup: func(as *autoStatus) {
+			as.success("web", actionKeep, now.Add(time.Hour), now.Add(time.Minute))
+			as.success("mail", actionKeep, now.Add(time.Hour), now.Add(time.Minute))
+			as.failed("mail", errors.New("boom"))
+		}},
+		{name: "stale", reasons: "web: certificate expired\n", setup: func(as *autoStatus) {
+			as.success("web", actionKeep, now.Add(-time.Minute), now.Add(-time.Hour))
+			as.success("mail", actionKeep, now.Add(time.Hour), now.Add(time.Minute))
+		}},
+		{name: "recovered", healthy: true, setup: func(as *autoStatus) {
+			as.failed("web", errors.New("boom"))
+			as.success("web", actionIssue, now.Add(time.Hour), now.Add(time.Minute))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newAutoStatus(groups, func() int64 { return 2 })
+			tt.setup(status)
+			srv := httptest.NewServer(status.handler())
+			defer srv.Close()
+			get := func(path string) *http.Response {
+				req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+path, nil)
+				require.NoError(t, err)
+				res, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				return res
+			}
+
+			res := get("/healthz")
+			body, err := io.ReadAll(res.Body)
+			_ = res.Body.Close()
+			require.NoError(t, err)
+			if tt.healthy {
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+				assert.Equal(t, "ok\n", string(body))
+			} else {
+				assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+				assert.Equal(t, tt.reasons, string(body))
+			}
+
+			res = get("/status")
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+			var report statusReport
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+			assert.Equal(t, tt.healthy, report.Healthy)
+			assert.Equal(t, int64(2), report.HookFailures)
+			require.Len(t, report.Certificates, len(groups))
+			assert.Equal(t, "web", report.Certificates[0].Name)
+			assert.Equal(t, []string{"mail.example.com"}, report.Certificates[1].Domains)
+		})
+	}
+}
+
+func TestCertStatus_Healthy(t *testing.T) {
+	past, future := time.Now().Add(-time.Second), time.Now().Add(time.Hour)
+	assert.True(t, (&certStatus{}).Healthy(), "not issued yet")
+	assert.True(t, (&certStatus{NotAfter: &future}).Healthy())
+	assert.False(t, (&certStatus{NotAfter: &past}).Healthy(), "expired")
+	assert.False(t, (&certStatus{NotAfter: &future, LastError: "boom"}).Healthy(), "failed")
+}
@@ -0,0 +1,369 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v4/providers/http/webroot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestCertGroup_same(t *testing.T) {
+	certPEM, _ := testCertPEM(t, time.Now().Add(time.Hour), "Example.com", "*.example.com")
+	block, _ := pem.Decode(certPEM)
+	crt, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		domains []string
+		same    bool
+	}{
+		{name: "exact", domains: []string{"Example.com", "*.example.com"}, same: true},
+		{name: "other order", domains: []string{"*.example.com", "Example.com"}, same: true},
+		{name: "other case", domains: []string{"example.com", "*.EXAMPLE.com"}, same: true},
+		{name: "missing domain", domains: []string{"example.com"}},
+		{name: "extra domain", domains: []string{"example.com", "*.example.com", "www.example.com"}},
+		{name: "other domain", domains: []string{"example.com", "www.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := CertGroup{Name: "example", Domains: tt.domains}
+			assert.Equal(t, tt.same, group.same(crt))
+		})
+	}
+}
+
+func TestCertKey(t *testing.T) {
+	wildcard := certKey(CertGroup{Name: "*.example.com", Domains: []string{"*.example.com"}})
+	assert.Regexp(t, `^_\.example\.com-[0-9a-f]{16}\.json$`, wildcard)
+
+	tests := []struct {
+		name  string
+		group CertGroup
+		same  bool
+	}{
+		{name: "sanitized name collision", group: CertGroup{Name: "_.example.com", Domains: []string{"_.example.com"}}},
+		{name: "same name other domains", group: CertGroup{Name: "*.example.com", Domains: []string{"*.example.com", "example.com"}}},
+		{name: "domains case", group: CertGroup{Name: "*.example.com", Domains: []string{"*.EXAMPLE.com"}}, same: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.same, certKey(tt.group) == wildcard)
+		})
+	}
+
+	t.Run("domains order", func(t *testing.T) {
+		a := certKey(CertGroup{Name: "example", Domains: []string{"example.com", "*.example.com"}})
+		b := certKey(CertGroup{Name: "example", Domains: []string{"*.example.com", "example.com"}})
+		assert.Equal(t, a, b)
+	})
+}
+
+func TestFindCertKey(t *testing.T) {
+	ctx := context.Background()
+	store := &dirStorage{dir: t.TempDir()}
+	example := CertGroup{Name: "example", Domains: []string{"example.com"}}
+	wildcard := CertGroup{Name: "*.example.com", Domains: []string{"*.example.com"}}
+	underscore := CertGroup{Name: "_.example.com", Domains: []string{"_.example.com"}}
+	for _, key := range []string{
+		certKey(example),
+		certKey(CertGroup{Name: "example-www", Domains: []string{"www.example.com"}}),
+		certKey(wildcard),
+		certKey(underscore),
+		legacyCertKey("legacy"),
+		"accounts/example/admin@example.com.json",
+	} {
+		require.NoError(t, store.Save(ctx, key, []byte("{}")))
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		err  error
+	}{
+		{name: "example", key: certKey(example)},
+		{name: "legacy", key: legacyCertKey("legacy")},
+		{name: "*.example.com", err: errCertKeyAmbiguous},
+		{name: "missing", err: os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := findCertKey(ctx, store, tt.name)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, key)
+		})
+	}
+}
+
+func TestCertsAuto_pushReason(t *testing.T) {
+	certPEM, keyPEM := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	renewedPEM, _ := testCertPEM(t, time.Now().Add(2*time.Hour), "example.com")
+	current := synoCert(t, "abc", "example", certPEM)
+	broken := current
+	broken.IsBroken = true
+	group := CertGroup{Name: "example", Domains: []string{"example.com"}}
+
+	tests := []struct {
+		name   string
+		force  bool
+		known  []client.Certificate
+		cached []byte
+		reason string
+	}{
+		{name: "up to date", known: []client.Certificate{current, synoCert(t, "def", "other", renewedPEM)}, cached: certPEM},
+		{name: "changed", known: []client.Certificate{current}, cached: renewedPEM, reason: "changed"},
+		{name: "missing", known: []client.Certificate{synoCert(t, "def", "other", certPEM)}, cached: certPEM, reason: "missing"},
+		{name: "broken", known: []client.Certificate{broken}, cached: certPEM, reason: "broken"},
+		{name: "ambiguous", known: []client.Certificate{current, synoCert(t, "def", "example", certPEM)}, cached: certPEM, reason: "ambiguous"},
+		{name: "forced", force: true, known: []client.Certificate{current}, cached: certPEM, reason: "forced"},
+		{name: "invalid cached certificate", known: []client.Certificate{current}, cached: []byte("not a certificate"), reason: "invalid cached certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &CertsAuto{ForcePush: tt.force}
+			mc := managedCert{Group: group, Resource: &certificate.Resource{PrivateKey: keyPEM, Certificate: tt.cached}}
+			assert.Equal(t, tt.reason, cmd.pushReason(tt.known, mc))
+		})
+	}
+}
+
+func TestCertsAuto_cycle_partialFailure(t *testing.T) {
+	ctx := context.Background()
+	certPEM, keyPEM := testCertPEM(t, time.Now().Add(90*24*time.Hour), "example.com")
+	var uploaded []string
+	syno := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+		switch api, method := request.FormValue("api"), request.FormValue("method"); {
+		case api == "SYNO.Core.Certificate.CRT" && method == "list":
+			return certsList(t)
+		case api == "SYNO.Core.Certificate" && method == "import":
+			uploaded = append(uploaded, request.FormValue("desc"))
+			return map[string]any{"id": "abc"}
+		}
+		assert.Fail(t, "unexpected call", "%s %s", request.FormValue("api"), request.FormValue("method"))
+		return apiErrorCode(100)
+	})
+
+	broken := CertGroup{Name: "broken", Domains: []string{"broken.example.com"}}
+	good := CertGroup{Name: "good", Domains: []string{"example.com"}}
+	store := &dirStorage{dir: t.TempDir()}
+	require.NoError(t, store.Save(ctx, certKey(broken), []byte("{")))
+	require.NoError(t, saveCert(ctx, store, certKey(good), &certificate.Resource{Domain: "example.com", PrivateKey: keyPEM, Certificate: certPEM}))
+
+	cmd := &CertsAuto{SynoClient: syno, Certs: []CertGroup{broken, good}, RenewBefore: 30 * 24 * time.Hour}
+	cmd.store = store
+	cmd.status = newAutoStatus(cmd.groups(), cmd.HookFailures)
+
+	err := cmd.cycle(ctx, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check certificate broken")
+	assert.Equal(t, []string{"good"}, uploaded, "succeeded group pushed")
+
+	report := cmd.status.report()
+	require.Len(t, report.Certificates, 2)
+	assert.NotEmpty(t, report.Certificates[0].LastError)
+	assert.Equal(t, "abc", report.Certificates[1].CertID)
+	assert.Empty(t, report.Certificates[1].LastError)
+}
+
+func TestParseCert(t *testing.T) {
+	certPEM, _ := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	crt, err := parseCert(&certificate.Resource{Certificate: certPEM})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, crt.DNSNames)
+
+	_, err = parseCert(&certificate.Resource{Certificate: []byte("not a certificate")})
+	assert.ErrorIs(t, err, errNoPEMCertificate)
+}
+
+func TestCertsAuto_dryRunNAS(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cmd := CertsAuto{
+		SynoClient: SynoClient{User: "admin", Password: "admin", URL: srv.URL, Timeout: time.Minute},
+		Domains:    []string{"example.com"},
+		DryRun:     true,
+	}
+	cmd.Storage = storageNAS
+	cmd.CacheDir = "/homes/admin/syno-cli"
+	require.NoError(t, cmd.Execute(nil))
+	assert.Zero(t, requests.Load(), "dry run should not call Synology")
+	report := cmd.status.report()
+	require.Len(t, report.Certificates, 1)
+	assert.NotNil(t, report.LastCycle)
+}
+
+func TestCertsAuto_challengeProvider(t *testing.T) {
+	webrootDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		cmd      *CertsAuto
+		provider any
+		err      string
+	}{
+		{name: "dns-01", cmd: &CertsAuto{Challenge: challengeDNS01, Provider: "manual"}, provider: &dns01.DNSProviderManual{}},
+		{name: "dns-01 without provider", cmd: &CertsAuto{Challenge: challengeDNS01}, err: "DNS provider should be set"},
+		{name: "dns-01 unknown provider", cmd: &CertsAuto{Challenge: challengeDNS01, Provider: "unknown"}, err: "unrecognized DNS provider"},
+		{name: "http-01 listener", cmd: &CertsAuto{Challenge: challengeHTTP01, HTTPListen: ":80"}, provider: &http01.ProviderServer{}},
+		{name: "http-01 invalid listen address", cmd: &CertsAuto{Challenge: challengeHTTP01, HTTPListen: "80"}, err: "parse HTTP listen address"},
+		{name: "http-01 webroot", cmd: &CertsAuto{Challenge: challengeHTTP01, HTTPListen: "80", Webroot: webrootDir}, provider: &webroot.HTTPProvider{}},
+		{name: "http-01 missing webroot", cmd: &CertsAuto{Challenge: challengeHTTP01, Webroot: filepath.Join(webrootDir, "missing")}, err: "does not exist"},
+		{name: "tls-alpn-01 listener", cmd: &CertsAuto{Challenge: challengeTLSALPN01, TLSListen: ":443"}, provider: &tlsalpn01.ProviderServer{}},
+		{name: "tls-alpn-01 invalid listen address", cmd: &CertsAuto{Challenge: challengeTLSALPN01, TLSListen: "localhost"}, err: "parse TLS listen address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := tt.cmd.challengeProvider()
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.provider, provider)
+		})
+	}
+}
+
+func TestCertsAuto_checkWildcards(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		domains   []string
+		certs     []CertGroup
+		err       bool
+	}{
+		{name: "dns-01 wildcard", challenge: challengeDNS01, domains: []string{"*.example.com"}},
+		{name: "http-01 plain", challenge: challengeHTTP01, domains: []string{"example.com"}, certs: []CertGroup{{Name: "www", Domains: []string{"www.example.com"}}}},
+		{name: "http-01 wildcard", challenge: challengeHTTP01, domains: []string{"*.example.com"}, err: true},
+		{name: "tls-alpn-01 wildcard in group", challenge: challengeTLSALPN01, certs: []CertGroup{{Name: "example", Domains: []string{"example.com", "*.example.com"}}}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := CertsAuto{Challenge: tt.challenge, Domains: tt.domains, Certs: tt.certs}
+			err := cmd.checkWildcards()
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestCertsAuto_challengeSolvers checks that built-in solvers answer challenges the same way as ACME server validates them.
+func TestCertsAuto_challengeSolvers(t *testing.T) {
+	const (
+		domain  = "example.com"
+		token   = "token"
+		keyAuth = "token.thumbprint"
+	)
+
+	t.Run(challengeHTTP01, func(t *testing.T) {
+		address := freeAddress(t)
+		cmd := CertsAuto{Challenge: challengeHTTP01, HTTPListen: address}
+		provider, err := cmd.challengeProvider()
+		require.NoError(t, err)
+		require.NoError(t, provider.Present(domain, token, keyAuth))
+		defer provider.CleanUp(domain, token, keyAuth) //nolint:errcheck
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+address+http01.ChallengePath(token), nil)
+		require.NoError(t, err)
+		req.Host = domain
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, keyAuth, string(body))
+	})
+
+	t.Run(challengeHTTP01+" webroot", func(t *testing.T) {
+		dir := t.TempDir()
+		cmd := CertsAuto{Challenge: challengeHTTP01, Webroot: dir}
+		provider, err := cmd.challengeProvider()
+		require.NoError(t, err)
+		require.NoError(t, provider.Present(domain, token, keyAuth))
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(http01.ChallengePath(token))))
+		require.NoError(t, err)
+		assert.Equal(t, keyAuth, string(content))
+		require.NoError(t, provider.CleanUp(domain, token, keyAuth))
+		assert.NoFileExists(t, filepath.Join(dir, filepath.FromSlash(http01.ChallengePath(token))))
+	})
+
+	t.Run(challengeTLSALPN01, func(t *testing.T) {
+		address := freeAddress(t)
+		cmd := CertsAuto{Challenge: challengeTLSALPN01, TLSListen: address}
+		provider, err := cmd.challengeProvider()
+		require.NoError(t, err)
+		require.NoError(t, provider.Present(domain, token, keyAuth))
+		defer provider.CleanUp(domain, token, keyAuth) //nolint:errcheck
+
+		conn, err := tls.Dial("tcp", address, &tls.Config{
+			ServerName:         domain,
+			NextProtos:         []string{tlsalpn01.ACMETLS1Protocol},
+			InsecureSkipVerify: true, //nolint:gosec // challenge certificate is self-signed
+			MinVersion:         tls.VersionTLS12,
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+		state := conn.ConnectionState()
+		assert.Equal(t, tlsalpn01.ACMETLS1Protocol, state.NegotiatedProtocol)
+		require.NotEmpty(t, state.PeerCertificates)
+		crt := state.PeerCertificates[0]
+		assert.Equal(t, []string{domain}, crt.DNSNames)
+
+		sum := sha256.Sum256([]byte(keyAuth))
+		expected, err := asn1.Marshal(sum[:])
+		require.NoError(t, err)
+		var found bool
+		for _, ext := range crt.Extensions {
+			if ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}) { // id-pe-acmeIdentifier
+				found = true
+				assert.True(t, ext.Critical)
+				assert.Equal(t, expected, ext.Value)
+			}
+		}
+		assert.True(t, found, "acmeIdentifier extension")
+	})
+}
+
+// freeAddress returns local address with unused port.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
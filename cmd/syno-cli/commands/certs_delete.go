@@ -31,19 +31,11 @@ func (lc *CertsDelete) Execute([]string) error {
 		return err
 	}
 
-	var certID string
-	for _, c := range list {
-		if c.ID == lc.Args.ID {
-			certID = c.ID
-			break
-		} else if c.Description == lc.Args.ID {
-			certID = c.ID
-		}
-	}
-
-	if certID == "" {
-		return fmt.Errorf("unknown name or id") //nolint:goerr113
+	crt, err := client.FindCert(list, lc.Args.ID)
+	if err != nil {
+		return err
 	}
+	certID := crt.ID
 
 	info, err := syno.DeleteCertByID(ctx, certID)
 	if err != nil {
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+//nolint:staticcheck
+type CertsMigrate struct {
+	SynoClient     `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	From           string `long:"from" env:"FROM" description:"Source storage" default:"dir" choice:"dir" choice:"age" choice:"nas"`
+	FromLocation   string `long:"from-location" env:"FROM_LOCATION" description:"Source cache location (directory or File Station path)" default:".cache"`
+	FromPassphrase string `long:"from-passphrase" env:"FROM_PASSPHRASE" description:"Passphrase for source age storage"`
+	To             string `long:"to" env:"TO" description:"Destination storage" required:"true" choice:"dir" choice:"age" choice:"nas"`
+	ToLocation     string `long:"to-location" env:"TO_LOCATION" description:"Destination cache location (directory or File Station path)" required:"true"`
+	ToPassphrase   string `long:"to-passphrase" env:"TO_PASSPHRASE" description:"Passphrase for destination age storage"`
+	Overwrite      bool   `long:"overwrite" env:"OVERWRITE" description:"Overwrite existent entries in destination"`
+}
+
+func (cmd *CertsMigrate) Execute([]string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	src, err := newStorage(cmd.From, cmd.FromLocation, cmd.FromPassphrase, cmd.Client)
+	if err != nil {
+		return fmt.Errorf("source storage: %w", err)
+	}
+	dst, err := newStorage(cmd.To, cmd.ToLocation, cmd.ToPassphrase, cmd.Client)
+	if err != nil {
+		return fmt.Errorf("destination storage: %w", err)
+	}
+
+	keys, err := src.Keys(ctx)
+	if err != nil {
+		return fmt.Errorf("list source entries: %w", err)
+	}
+
+	var copied, skipped int
+	for _, key := range keys {
+		if !cmd.Overwrite {
+			_, err := dst.Load(ctx, key)
+			if err == nil {
+				slog.Info("entry already exists, skipped", "key", key, "location", dst.Location(key))
+				skipped++
+				continue
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("check destination entry %s: %w", key, err)
+			}
+		}
+		data, err := src.Load(ctx, key)
+		if err != nil {
+			return fmt.Errorf("load entry %s: %w", key, err)
+		}
+		if err := dst.Save(ctx, key, data); err != nil {
+			return fmt.Errorf("save entry %s: %w", key, err)
+		}
+		slog.Info("entry migrated", "key", key, "from", src.Location(key), "to", dst.Location(key))
+		copied++
+	}
+	slog.Info("migration complete", "copied", copied, "skipped", skipped)
+	return nil
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertsMigrate(t *testing.T) {
+	const nasFolder = "/homes/admin/syno-cli"
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		existing  map[string]string // entries in destination before migration
+		expected  map[string]string
+	}{
+		{
+			name: "empty destination",
+			expected: map[string]string{
+				"example.json":               "certificate",
+				"accounts/server/admin.json": "account",
+			},
+		},
+		{
+			name:     "keep existent",
+			existing: map[string]string{"example.json": "newer certificate"},
+			expected: map[string]string{
+				"example.json":               "newer certificate",
+				"accounts/server/admin.json": "account",
+			},
+		},
+		{
+			name:      "overwrite existent",
+			overwrite: true,
+			existing:  map[string]string{"example.json": "newer certificate", "other.json": "other"},
+			expected: map[string]string{
+				"example.json":               "certificate",
+				"accounts/server/admin.json": "account",
+				"other.json":                 "other",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var files fakeFileStation
+			syno := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+				return files.handle(t, request)
+			})
+
+			srcDir := t.TempDir()
+			src := &dirStorage{dir: srcDir}
+			require.NoError(t, src.Save(ctx, "example.json", []byte("certificate")))
+			require.NoError(t, src.Save(ctx, "accounts/server/admin.json", []byte("account")))
+
+			dst, err := newStorage(storageNAS, nasFolder, "", syno.Client)
+			require.NoError(t, err)
+			for key, value := range tt.existing {
+				require.NoError(t, dst.Save(ctx, key, []byte(value)))
+			}
+
+			cmd := CertsMigrate{
+				SynoClient:   syno,
+				From:         storageDir,
+				FromLocation: srcDir,
+				To:           storageNAS,
+				ToLocation:   nasFolder,
+				Overwrite:    tt.overwrite,
+			}
+			require.NoError(t, cmd.Execute(nil))
+
+			var actual = make(map[string]string)
+			for name, content := range files.files {
+				actual[name[len(nasFolder)+1:]] = string(content)
+			}
+			assert.Equal(t, tt.expected, actual)
+
+			// the reverse direction restores the same content
+			back := CertsMigrate{
+				SynoClient:   syno,
+				From:         storageNAS,
+				FromLocation: nasFolder,
+				To:           storageDir,
+				ToLocation:   t.TempDir(),
+			}
+			require.NoError(t, back.Execute(nil))
+			restored := &dirStorage{dir: back.ToLocation}
+			for key, value := range tt.expected {
+				data, err := restored.Load(ctx, key)
+				require.NoError(t, err)
+				assert.Equal(t, value, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+//nolint:staticcheck
+type CertsPrune struct {
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Yes        bool `short:"y" long:"yes" env:"YES" description:"Do not ask for confirmation"`
+}
+
+func (cmd *CertsPrune) Execute([]string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	syno := cmd.Client()
+	list, err := syno.ListCerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var candidates []client.Certificate
+	for _, crt := range list {
+		if prunable(crt) {
+			candidates = append(candidates, crt)
+		}
+	}
+
+	if len(candidates) == 0 {
+		slog.Info("nothing to prune")
+		return nil
+	}
+
+	if err := cmd.show(candidates); err != nil {
+		return err
+	}
+
+	if !cmd.Yes && !confirm(fmt.Sprintf("Delete %d certificate(s)?", len(candidates))) {
+		slog.Info("cancelled")
+		return nil
+	}
+
+	for _, crt := range candidates {
+		info, err := syno.DeleteCertByID(ctx, crt.ID)
+		if err != nil {
+			return fmt.Errorf("delete certificate %s (%s): %w", crt.ID, crt.Description, err)
+		}
+		slog.Info("certificate deleted", "certificate_id", crt.ID, "name", crt.Description, "server_restarted", info.ServerRestarted)
+	}
+	return nil
+}
+
+// prunable checks that certificate is expired or broken, not used by any service and can be deleted.
+func prunable(crt client.Certificate) bool {
+	return (crt.Expired() || crt.IsBroken) && len(crt.Services) == 0 && !crt.IsDefault && crt.UserDeletable
+}
+
+//nolint:gomnd
+func (cmd *CertsPrune) show(list []client.Certificate) error {
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"ID", "\t",
+		"Name", "\t",
+		"Broken", "\t",
+		"Expired", "\t",
+	)
+	for _, item := range list {
+		_, _ = fmt.Fprintln(tw,
+			item.ID, "\t",
+			item.Description, "\t",
+			item.IsBroken, "\t",
+			item.ValidTill.Time().Format(time.RFC822), "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+// confirm asks user in terminal. Only y or yes (case-insensitive) accepted as confirmation.
+func confirm(question string) bool {
+	_, _ = fmt.Fprint(os.Stderr, question, " [y/N]: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestPrunable(t *testing.T) {
+	expired := client.CTime(time.Now().Add(-time.Hour))
+	valid := client.CTime(time.Now().Add(time.Hour))
+	service := client.Service{Service: "default", DisplayName: "DSM"}
+
+	tests := []struct {
+		name     string
+		crt      client.Certificate
+		prunable bool
+	}{
+		{name: "expired", crt: client.Certificate{ValidTill: expired, UserDeletable: true}, prunable: true},
+		{name: "broken", crt: client.Certificate{ValidTill: valid, IsBroken: true, UserDeletable: true}, prunable: true},
+		{name: "valid", crt: client.Certificate{ValidTill: valid, UserDeletable: true}},
+		{name: "expired in use", crt: client.Certificate{ValidTill: expired, UserDeletable: true, Services: []client.Service{service}}},
+		{name: "expired default", crt: client.Certificate{ValidTill: expired, UserDeletable: true, IsDefault: true}},
+		{name: "expired not deletable", crt: client.Certificate{ValidTill: expired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.prunable, prunable(tt.crt))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/go-acme/lego/v4/certificate"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+//nolint:staticcheck
+type CertsRevoke struct {
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	ACMEAccount
+	KeepNAS bool `long:"keep-nas" env:"KEEP_NAS" description:"Do not delete revoked certificate from Synology"`
+	Args    struct {
+		Name string `positional-arg-name:"domain" env:"NAME" description:"certificate name in cache (domain or group name used in cert auto)" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (cmd *CertsRevoke) Execute([]string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	if err := cmd.openStorage(cmd.Client); err != nil {
+		return err
+	}
+
+	key, err := findCertKey(ctx, cmd.store, cmd.Args.Name)
+	if err != nil {
+		return fmt.Errorf("find cached certificate %s: %w", cmd.Args.Name, err)
+	}
+	res, err := loadCert(ctx, cmd.store, key)
+	if err != nil {
+		return fmt.Errorf("load cached certificate %s: %w", cmd.Args.Name, err)
+	}
+
+	lgc, err := cmd.getOrCreateAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("revoking certificate", "name", cmd.Args.Name, "domain", res.Domain)
+	if err := lgc.Certificate.Revoke(res.Certificate); err != nil {
+		return fmt.Errorf("revoke certificate: %w", err)
+	}
+
+	return cmd.removeRevoked(ctx, key, res)
+}
+
+// removeRevoked deletes revoked certificate from Synology (unless disabled) and then from cache, so next
+// cert auto cycle will issue new one. Certificate in Synology is deleted only if it is the revoked one.
+// Cache entry is kept if Synology deletion failed, so removal can be retried.
+func (cmd *CertsRevoke) removeRevoked(ctx context.Context, key string, res *certificate.Resource) error {
+	if !cmd.KeepNAS {
+		if err := cmd.deleteFromNAS(ctx, res); err != nil {
+			return fmt.Errorf("delete certificate from Synology: %w", err)
+		}
+	}
+	if err := cmd.store.Delete(ctx, key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove certificate from cache: %w", err)
+	}
+	return nil
+}
+
+func (cmd *CertsRevoke) deleteFromNAS(ctx context.Context, res *certificate.Resource) error {
+	leaf, err := parseCert(res)
+	if err != nil {
+		return fmt.Errorf("parse revoked certificate: %w", err)
+	}
+	syno := cmd.Client()
+	list, err := syno.ListCerts(ctx)
+	if err != nil {
+		return fmt.Errorf("list certificates: %w", err)
+	}
+	found := client.FindCertsByName(list, cmd.Args.Name)
+	switch len(found) {
+	case 0:
+		slog.Info("certificate not found in Synology", "name", cmd.Args.Name)
+		return nil
+	case 1:
+	default:
+		return fmt.Errorf("%w: %q", client.ErrCertAmbiguous, cmd.Args.Name)
+	}
+	if !found[0].Matches(leaf) {
+		slog.Warn("certificate in Synology is not the revoked one, keeping it", "name", cmd.Args.Name, "certificate_id", found[0].ID)
+		return nil
+	}
+
+	info, err := syno.DeleteCertByID(ctx, found[0].ID)
+	if err != nil {
+		return err
+	}
+	slog.Info("certificate deleted from Synology", "certificate_id", found[0].ID, "server_restarted", info.ServerRestarted)
+	return nil
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestCertsRevoke_removeRevoked(t *testing.T) {
+	certPEM, keyPEM := testCertPEM(t, time.Now().Add(time.Hour), "example.com")
+	otherPEM, _ := testCertPEM(t, time.Now().Add(2*time.Hour), "example.com")
+	revoked := &certificate.Resource{Domain: "example.com", PrivateKey: keyPEM, Certificate: certPEM}
+	group := CertGroup{Name: "example.com", Domains: []string{"example.com"}}
+
+	tests := []struct {
+		name    string
+		keepNAS bool
+		certs   []client.Certificate
+		failed  bool // Synology fails to delete certificate
+		deleted []string
+		cached  bool
+		err     string
+	}{
+		{name: "revoked in Synology", certs: []client.Certificate{synoCert(t, "abc", "example.com", certPEM), synoCert(t, "def", "other", certPEM)}, deleted: []string{"abc"}},
+		{name: "not in Synology", certs: []client.Certificate{synoCert(t, "def", "other", certPEM)}},
+		{name: "other certificate in Synology", certs: []client.Certificate{synoCert(t, "abc", "example.com", otherPEM)}},
+		{name: "keep Synology", keepNAS: true, certs: []client.Certificate{synoCert(t, "abc", "example.com", certPEM)}},
+		{name: "ambiguous", certs: []client.Certificate{synoCert(t, "abc", "example.com", certPEM), synoCert(t, "def", "example.com", certPEM)}, cached: true, err: client.ErrCertAmbiguous.Error()},
+		{name: "delete failed", certs: []client.Certificate{synoCert(t, "abc", "example.com", certPEM)}, failed: true, cached: true, err: "API error code: 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var deleted []string
+			syno := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+				switch api, method := request.FormValue("api"), request.FormValue("method"); {
+				case api == "SYNO.Core.Certificate.CRT" && method == "list":
+					return certsList(t, tt.certs...)
+				case api == "SYNO.Core.Certificate.CRT" && method == "delete":
+					if tt.failed {
+						return apiErrorCode(100)
+					}
+					var ids []string
+					assert.NoError(t, json.Unmarshal([]byte(request.FormValue("ids")), &ids))
+					deleted = append(deleted, ids...)
+					return map[string]any{"restart_httpd": false}
+				}
+				assert.Fail(t, "unexpected call", "%s %s", request.FormValue("api"), request.FormValue("method"))
+				return apiErrorCode(100)
+			})
+
+			store := &dirStorage{dir: t.TempDir()}
+			key := certKey(group)
+			require.NoError(t, saveCert(ctx, store, key, revoked))
+
+			cmd := CertsRevoke{SynoClient: syno, KeepNAS: tt.keepNAS}
+			cmd.store = store
+			cmd.Args.Name = group.Name
+
+			err := cmd.removeRevoked(ctx, key, revoked)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.deleted, deleted)
+			_, err = store.Load(ctx, key)
+			if tt.cached {
+				assert.NoError(t, err, "cache entry kept")
+			} else {
+				assert.ErrorIs(t, err, os.ErrNotExist, "cache entry removed")
+			}
+		})
+	}
+}
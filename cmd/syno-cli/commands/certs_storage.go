@@ -0,0 +1,292 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	storageDir = "dir"
+	storageAge = "age"
+	storageNAS = "nas"
+)
+
+var errReadOnlyStorage = errors.New("storage is read-only")
+
+// certStorage keeps ACME accounts and issued certificates (including private keys).
+// Keys are slash separated relative paths (ex: accounts/server/email.json).
+type certStorage interface {
+	// Load content by key. Returns fs.ErrNotExist if key not found.
+	Load(ctx context.Context, key string) ([]byte, error)
+	// Save content by key, replacing existent.
+	Save(ctx context.Context, key string, data []byte) error
+	// Delete content by key. Returns fs.ErrNotExist if key not found.
+	Delete(ctx context.Context, key string) error
+	// Keys returns all stored keys.
+	Keys(ctx context.Context) ([]string, error)
+	// Location is human-readable location of key (for logs and hooks).
+	Location(key string) string
+}
+
+// newStorage creates storage by kind. Location is local directory for dir and age storages, or
+// File Station path for nas storage. Passphrase is used only by age storage.
+func newStorage(kind, location, passphrase string, syno func() *client.Client) (certStorage, error) {
+	switch kind {
+	case storageDir:
+		return &dirStorage{dir: location}, nil
+	case storageAge:
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase required for %s storage", storageAge) //nolint:goerr113
+		}
+		return &ageStorage{dir: dirStorage{dir: location}, passphrase: passphrase}, nil
+	case storageNAS:
+		if !strings.HasPrefix(location, "/") {
+			return nil, fmt.Errorf("%s storage location should be absolute path starting with shared folder (ex: /homes/admin/syno-cli)", storageNAS) //nolint:goerr113
+		}
+		return &nasStorage{folder: location, files: syno().FileStation()}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q", kind) //nolint:goerr113
+	}
+}
+
+// dirStorage keeps plain files in local directory, accessible only by owner.
+type dirStorage struct {
+	dir string
+}
+
+// Load content by key. Files created by older versions or copied manually could be readable by others,
+// so permissions are tightened to owner only.
+func (ds *dirStorage) Load(_ context.Context, key string) ([]byte, error) {
+	f, err := os.Open(ds.Location(key))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		if err := f.Chmod(0600); err != nil {
+			return nil, fmt.Errorf("restrict permissions of %s: %w", key, err)
+		}
+	}
+	return io.ReadAll(f)
+}
+
+func (ds *dirStorage) Save(_ context.Context, key string, data []byte) error {
+	file := ds.Location(key)
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	tempFile := file + ".tmp"
+	f, err := os.OpenFile(tempFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile)
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, file)
+}
+
+func (ds *dirStorage) Delete(_ context.Context, key string) error {
+	return os.Remove(ds.Location(key))
+}
+
+func (ds *dirStorage) Keys(_ context.Context) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(ds.dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(file, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(ds.dir, file)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+func (ds *dirStorage) Location(key string) string {
+	return filepath.Join(ds.dir, filepath.FromSlash(key))
+}
+
+// ageStorage keeps files in local directory, encrypted by passphrase using age (https://age-encryption.org).
+type ageStorage struct {
+	dir        dirStorage
+	passphrase string
+}
+
+func (as *ageStorage) Load(ctx context.Context, key string) ([]byte, error) {
+	data, err := as.dir.Load(ctx, key+".age")
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(as.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("create identity: %w", err)
+	}
+	reader, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", key, err)
+	}
+	return io.ReadAll(reader)
+}
+
+func (as *ageStorage) Save(ctx context.Context, key string, data []byte) error {
+	recipient, err := age.NewScryptRecipient(as.passphrase)
+	if err != nil {
+		return fmt.Errorf("create recipient: %w", err)
+	}
+	var buffer bytes.Buffer
+	writer, err := age.Encrypt(&buffer, recipient)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", key, err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("encrypt %s: %w", key, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("encrypt %s: %w", key, err)
+	}
+	return as.dir.Save(ctx, key+".age", buffer.Bytes())
+}
+
+func (as *ageStorage) Delete(ctx context.Context, key string) error {
+	return as.dir.Delete(ctx, key+".age")
+}
+
+func (as *ageStorage) Keys(ctx context.Context) ([]string, error) {
+	list, err := as.dir.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var keys = make([]string, 0, len(list))
+	for _, key := range list {
+		if name, ok := strings.CutSuffix(key, ".age"); ok {
+			keys = append(keys, name)
+		}
+	}
+	return keys, nil
+}
+
+func (as *ageStorage) Location(key string) string {
+	return as.dir.Location(key + ".age")
+}
+
+// nasStorage keeps files on Synology itself using File Station.
+type nasStorage struct {
+	folder string
+	files  *client.FileStation
+}
+
+func (ns *nasStorage) Load(ctx context.Context, key string) ([]byte, error) {
+	stream, err := ns.files.Download(ctx, path.Join(ns.folder, key))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+func (ns *nasStorage) Save(ctx context.Context, key string, data []byte) error {
+	return ns.files.Upload(ctx, path.Join(ns.folder, key), bytes.NewReader(data))
+}
+
+func (ns *nasStorage) Delete(ctx context.Context, key string) error {
+	return ns.files.Delete(ctx, path.Join(ns.folder, key))
+}
+
+func (ns *nasStorage) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	var queue = []string{ns.folder}
+	for len(queue) > 0 {
+		folder := queue[0]
+		queue = queue[1:]
+		list, err := ns.files.List(ctx, folder)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", folder, err)
+		}
+		for _, item := range list {
+			if item.IsDir {
+				queue = append(queue, item.Path)
+				continue
+			}
+			keys = append(keys, strings.TrimPrefix(strings.TrimPrefix(item.Path, ns.folder), "/"))
+		}
+	}
+	return keys, nil
+}
+
+func (ns *nasStorage) Location(key string) string {
+	return "nas:" + path.Join(ns.folder, key)
+}
+
+// emptyStorage is read-only storage without entries.
+type emptyStorage struct{}
+
+func (emptyStorage) Load(context.Context, string) ([]byte, error) {
+	return nil, fs.ErrNotExist
+}
+
+func (emptyStorage) Save(context.Context, string, []byte) error {
+	return errReadOnlyStorage
+}
+
+func (emptyStorage) Delete(context.Context, string) error {
+	return fs.ErrNotExist
+}
+
+func (emptyStorage) Keys(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (emptyStorage) Location(key string) string {
+	return "empty:" + key
+}
+
+func loadJSON(ctx context.Context, store certStorage, key string, out interface{}) error {
+	data, err := store.Load(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func saveJSON(ctx context.Context, store certStorage, key string, data interface{}) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(ctx, key, content)
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	tests := []struct {
+		kind       string
+		passphrase string
+		location   func(t *testing.T) string
+	}{
+		{kind: storageDir, location: func(t *testing.T) string { return t.TempDir() }},
+		{kind: storageAge, passphrase: "secret", location: func(t *testing.T) string { return t.TempDir() }},
+		{kind: storageNAS, location: func(*testing.T) string { return "/homes/admin/syno-cli" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			ctx := context.Background()
+			var files fakeFileStation
+			syno := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+				return files.handle(t, request)
+			})
+			store, err := newStorage(tt.kind, tt.location(t), tt.passphrase, syno.Client)
+			require.NoError(t, err)
+
+			keys, err := store.Keys(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+			_, err = store.Load(ctx, "example.json")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+
+			require.NoError(t, store.Save(ctx, "example.json", []byte("first")))
+			require.NoError(t, store.Save(ctx, "example.json", []byte("second")))
+			require.NoError(t, store.Save(ctx, "accounts/server/admin@example.com.json", []byte("account")))
+
+			data, err := store.Load(ctx, "example.json")
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(data))
+			data, err = store.Load(ctx, "accounts/server/admin@example.com.json")
+			require.NoError(t, err)
+			assert.Equal(t, "account", string(data))
+
+			keys, err = store.Keys(ctx)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"example.json", "accounts/server/admin@example.com.json"}, keys)
+
+			require.NoError(t, store.Delete(ctx, "example.json"))
+			assert.ErrorIs(t, store.Delete(ctx, "example.json"), fs.ErrNotExist)
+			_, err = store.Load(ctx, "example.json")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+			keys, err = store.Keys(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"accounts/server/admin@example.com.json"}, keys)
+		})
+	}
+}
+
+func TestNewStorage_invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		kind       string
+		location   string
+		passphrase string
+	}{
+		{name: "age without passphrase", kind: storageAge, location: ".cache"},
+		{name: "nas relative path", kind: storageNAS, location: "homes/admin"},
+		{name: "unknown kind", kind: "s3", location: ".cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newStorage(tt.kind, tt.location, tt.passphrase, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDirStorage_permissions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := &dirStorage{dir: dir}
+
+	require.NoError(t, store.Save(ctx, "accounts/admin.json", []byte("{}")))
+	info, err := os.Stat(filepath.Join(dir, "accounts"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dir, "accounts", "admin.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// created by older version or copied manually
+	legacy := filepath.Join(dir, "example.json")
+	require.NoError(t, os.WriteFile(legacy, []byte("{}"), 0600))
+	require.NoError(t, os.Chmod(legacy, 0644))
+	data, err := store.Load(ctx, "example.json")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+	info, err = os.Stat(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// stricter permissions are kept
+	require.NoError(t, os.Chmod(legacy, 0400))
+	_, err = store.Load(ctx, "example.json")
+	require.NoError(t, err)
+	info, err = os.Stat(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+}
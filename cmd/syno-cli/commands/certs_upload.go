@@ -14,13 +14,20 @@ import (
 
 //nolint:staticcheck
 type CertsUpload struct {
-	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
-	Key        string `short:"k" long:"key" env:"KEY" description:"Path to private key. Use - (dash) to read it from stdin" default:"-"`
-	Cert       string `short:"c" long:"cert" env:"CERT" description:"Path to server certificate" required:"true"`
-	CA         string `short:"C" long:"ca" env:"CA" description:"Path to intermediate certificate"`
-	Format     string `short:"f" long:"format" env:"FORMAT" description:"Output format" default:"table" choice:"table" choice:"json"`
-	Default    bool   `short:"d" long:"default" env:"DEFAULT" description:"Set certificate as default"`
-	Args       struct {
+	SynoClient     `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Key            string `short:"k" long:"key" env:"KEY" description:"Path to private key. Use - (dash) to read it from stdin" default:"-"`
+	Cert           string `short:"c" long:"cert" env:"CERT" description:"Path to server certificate"`
+	CA             string `short:"C" long:"ca" env:"CA" description:"Path to intermediate certificate"`
+	Bundle         string `short:"b" long:"bundle" env:"BUNDLE" description:"Path to combined PEM (leaf, chain and optionally key) instead of --cert and --ca"`
+	PFX            string `short:"p" long:"pfx" env:"PFX" description:"Path to PKCS#12 (PFX) file instead of --key, --cert and --ca"`
+	PFXPassword    string `short:"P" long:"pfx-password" env:"PFX_PASSWORD" description:"Password for PKCS#12 (PFX) file"`
+	Format         string `short:"f" long:"format" env:"FORMAT" description:"Output format" default:"table" choice:"table" choice:"json"`
+	Default        bool   `short:"d" long:"default" env:"DEFAULT" description:"Set certificate as default"`
+	SkipValidation bool   `long:"skip-validation" env:"SKIP_VALIDATION" description:"Do not validate key, certificate and CA locally before upload"`
+	ID             string `long:"id" env:"ID" description:"Replace certificate with the specified ID"`
+	CreateOnly     bool   `long:"create-only" env:"CREATE_ONLY" description:"Fail if certificate with the same name already exists"`
+	Replace        bool   `long:"replace" env:"REPLACE" description:"Replace certificate with the same name; fail if it does not exist or name is ambiguous"`
+	Args           struct {
 		Name string `positional-arg-name:"name" env:"NAME" description:"certificate name" required:"true"`
 	} `positional-args:"true"`
 }
@@ -29,46 +36,130 @@ func (lc *CertsUpload) Execute([]string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	syno := lc.Client()
+	if countTrue(lc.ID != "", lc.CreateOnly, lc.Replace) > 1 {
+		return fmt.Errorf("only one of --id, --create-only or --replace can be set") //nolint:goerr113
+	}
 
-	var privateFile io.ReadCloser
-	if lc.Key == "-" {
-		privateFile = os.Stdin
-	} else if f, err := os.Open(lc.Key); err == nil {
-		privateFile = f
-	} else {
+	bundle, err := lc.loadBundle()
+	if err != nil {
 		return err
 	}
-	defer privateFile.Close()
 
-	certFile, err := os.Open(lc.Cert)
+	syno := lc.Client()
+
+	draft := bundle.NewCertificate(lc.Args.Name, lc.Default)
+	draft.SkipValidation = lc.SkipValidation
+	draft.Mode, draft.ID = lc.uploadMode()
+
+	info, err := syno.UploadCert(ctx, draft)
 	if err != nil {
 		return err
 	}
-	defer certFile.Close()
 
-	var caFile io.Reader
-	if lc.CA != "" {
-		f, err := os.Open(lc.CA)
+	return lc.show(info)
+}
+
+func (lc *CertsUpload) uploadMode() (client.UploadMode, string) {
+	switch {
+	case lc.ID != "":
+		return client.UploadReplaceByID, lc.ID
+	case lc.CreateOnly:
+		return client.UploadCreateOnly, ""
+	case lc.Replace:
+		return client.UploadReplaceByName, ""
+	default:
+		return client.UploadUpsert, ""
+	}
+}
+
+// checkSources rejects conflicting sources of key and certificates.
+func (lc *CertsUpload) checkSources() error {
+	switch {
+	case lc.PFX != "" && (lc.Bundle != "" || lc.Cert != "" || lc.CA != "" || lc.explicitKey()):
+		return fmt.Errorf("--pfx could not be used with --bundle, --cert, --ca or --key") //nolint:goerr113
+	case lc.Bundle != "" && (lc.Cert != "" || lc.CA != ""):
+		return fmt.Errorf("--bundle could not be used with --cert or --ca") //nolint:goerr113
+	case lc.PFXPassword != "" && lc.PFX == "":
+		return fmt.Errorf("--pfx-password could be used only with --pfx") //nolint:goerr113
+	case lc.CA != "" && lc.Cert == "":
+		return fmt.Errorf("--ca could be used only with --cert") //nolint:goerr113
+	}
+	return nil
+}
+
+// explicitKey is true if key is set to file instead of default STDIN.
+func (lc *CertsUpload) explicitKey() bool {
+	return lc.Key != "" && lc.Key != "-"
+}
+
+func (lc *CertsUpload) loadBundle() (*client.CertBundle, error) {
+	if err := lc.checkSources(); err != nil {
+		return nil, err
+	}
+	if lc.PFX != "" {
+		data, err := os.ReadFile(lc.PFX)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		caFile = f
-		defer f.Close()
+		return client.ParsePKCS12(data, lc.PFXPassword)
 	}
 
-	info, err := syno.UploadCert(ctx, client.NewCertificate{
-		Name:      lc.Args.Name,
-		AsDefault: lc.Default,
-		Cert:      certFile,
-		CA:        caFile,
-		Key:       privateFile,
-	})
+	var bundle *client.CertBundle
+	if lc.Bundle != "" {
+		data, err := os.ReadFile(lc.Bundle)
+		if err != nil {
+			return nil, err
+		}
+		bundle, err = client.ParsePEMBundle(data)
+		if err != nil {
+			return nil, err
+		}
+	} else if lc.Cert != "" {
+		cert, err := os.ReadFile(lc.Cert)
+		if err != nil {
+			return nil, err
+		}
+		bundle = &client.CertBundle{Cert: cert}
+		if lc.CA != "" {
+			bundle.Chain, err = os.ReadFile(lc.CA)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("one of --cert, --bundle or --pfx should be set") //nolint:goerr113
+	}
+
+	if len(bundle.Key) > 0 {
+		if lc.explicitKey() {
+			return nil, fmt.Errorf("bundle already contains private key, --key could not be used") //nolint:goerr113
+		}
+		return bundle, nil
+	}
+
+	key, err := readFileOrStdin(lc.Key)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	bundle.Key = key
+	return bundle, nil
+}
 
-	return lc.show(info)
+func countTrue(flags ...bool) int {
+	var n int
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	return n
+}
+
+func readFileOrStdin(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(file)
 }
 
 //nolint:gomnd
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestCertsUpload_loadBundle(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM := testCertPEM(t, time.Now().Add(24*time.Hour), "example.com")
+	write := func(name string, content ...[]byte) string {
+		file := filepath.Join(dir, name)
+		var data []byte
+		for _, c := range content {
+			data = append(data, c...)
+		}
+		require.NoError(t, os.WriteFile(file, data, 0600))
+		return file
+	}
+	cert := write("cert.pem", certPEM)
+	key := write("key.pem", keyPEM)
+	ca := write("ca.pem", certPEM)
+	bundle := write("bundle.pem", certPEM)
+	bundleWithKey := write("bundle-key.pem", keyPEM, certPEM)
+	const pfx = "cert.pfx" // not read: rejected before
+
+	cases := []struct {
+		name string
+		cmd  CertsUpload
+		err  string
+	}{
+		{name: "cert and key", cmd: CertsUpload{Cert: cert, Key: key}},
+		{name: "cert, ca and key", cmd: CertsUpload{Cert: cert, CA: ca, Key: key}},
+		{name: "bundle and key", cmd: CertsUpload{Bundle: bundle, Key: key}},
+		{name: "bundle with key", cmd: CertsUpload{Bundle: bundleWithKey, Key: "-"}},
+		{name: "nothing", cmd: CertsUpload{Key: key}, err: "one of --cert, --bundle or --pfx should be set"},
+		{name: "pfx and cert", cmd: CertsUpload{PFX: pfx, Cert: cert, Key: "-"}, err: "--pfx could not be used"},
+		{name: "pfx and ca", cmd: CertsUpload{PFX: pfx, CA: ca, Key: "-"}, err: "--pfx could not be used"},
+		{name: "pfx and bundle", cmd: CertsUpload{PFX: pfx, Bundle: bundle, Key: "-"}, err: "--pfx could not be used"},
+		{name: "pfx and key", cmd: CertsUpload{PFX: pfx, Key: key}, err: "--pfx could not be used"},
+		{name: "bundle and cert", cmd: CertsUpload{Bundle: bundle, Cert: cert, Key: key}, err: "--bundle could not be used"},
+		{name: "bundle and ca", cmd: CertsUpload{Bundle: bundle, CA: ca, Key: key}, err: "--bundle could not be used"},
+		{name: "bundle with key and key", cmd: CertsUpload{Bundle: bundleWithKey, Key: key}, err: "bundle already contains private key"},
+		{name: "pfx password without pfx", cmd: CertsUpload{Cert: cert, Key: key, PFXPassword: "secret"}, err: "--pfx-password could be used only with --pfx"},
+		{name: "ca without cert", cmd: CertsUpload{CA: ca, Key: key}, err: "--ca could be used only with --cert"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			loaded, err := tc.cmd.loadBundle()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, keyPEM, loaded.Key)
+			require.Equal(t, certPEM, loaded.Cert)
+		})
+	}
+}
+
+func TestCertsUpload_uploadMode(t *testing.T) {
+	cases := []struct {
+		name string
+		cmd  CertsUpload
+		mode client.UploadMode
+		id   string
+	}{
+		{name: "default", mode: client.UploadUpsert},
+		{name: "create only", cmd: CertsUpload{CreateOnly: true}, mode: client.UploadCreateOnly},
+		{name: "replace by name", cmd: CertsUpload{Replace: true}, mode: client.UploadReplaceByName},
+		{name: "replace by id", cmd: CertsUpload{ID: "a1"}, mode: client.UploadReplaceByID, id: "a1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mode, id := tc.cmd.uploadMode()
+			require.Equal(t, tc.mode, mode)
+			require.Equal(t, tc.id, id)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	switchOn  = "on"
+	switchOff = "off"
+)
+
+// rateLimit is speed limit in KB/s. Parsed from number with optional unit (K, M, G; ex: 512K, 1.5M).
+// Number without unit means KB/s. 0 means unlimited.
+type rateLimit int64
+
+func (rl *rateLimit) UnmarshalFlag(input string) error {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "/S"), "B")
+	var multiplier float64 = 1
+	switch {
+	case strings.HasSuffix(value, "K"):
+		value = strings.TrimSuffix(value, "K")
+	case strings.HasSuffix(value, "M"):
+		value = strings.TrimSuffix(value, "M")
+		multiplier = 1024
+	case strings.HasSuffix(value, "G"):
+		value = strings.TrimSuffix(value, "G")
+		multiplier = 1024 * 1024
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || num < 0 {
+		return fmt.Errorf("invalid speed limit %q, expected number with optional unit K, M or G (ex: 5M)", input) //nolint:goerr113
+	}
+	*rl = rateLimit(num * multiplier)
+	return nil
+}
+
+func (rl *rateLimit) ptr() *int64 {
+	if rl == nil {
+		return nil
+	}
+	v := int64(*rl)
+	return &v
+}
+
+// formatLimit formats speed limit in KB/s.
+func formatLimit(limit int64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return humanSpeed(limit * 1024) //nolint:gomnd
+}
+
+func switchValue(value *string) *bool {
+	if value == nil {
+		return nil
+	}
+	on := *value == switchOn
+	return &on
+}
+
+func formatSwitch(on bool) string {
+	if on {
+		return switchOn
+	}
+	return switchOff
+}
+
+// showKeyValues prints rows as aligned key-value pairs.
+//
+//nolint:gomnd
+func showKeyValues(rows [][2]string) error {
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, row[0]+":", "\t", row[1])
+	}
+	return tw.Flush()
+}
+
+type DsConfigGet struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Format     string `short:"f" long:"format" env:"FORMAT" description:"How to show output" default:"table" choice:"table" choice:"json"`
+}
+
+func (cmd *DsConfigGet) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	ds := cmd.Client().DownloadStation()
+	info, err := ds.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("get info: %w", err)
+	}
+	config, err := ds.Config(ctx)
+	if err != nil {
+		return fmt.Errorf("get config: %w", err)
+	}
+	if cmd.Format == fmtJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Info   *client.StationInfo   `json:"info"`
+			Config *client.StationConfig `json:"config"`
+		}{Info: info, Config: config})
+	}
+	return showKeyValues([][2]string{
+		{"Version", info.VersionString},
+		{"Manager", strconv.FormatBool(info.IsManager)},
+		{"Default destination", config.DefaultDestination},
+		{"BT max download", formatLimit(config.BTMaxDownload)},
+		{"BT max upload", formatLimit(config.BTMaxUpload)},
+		{"HTTP max download", formatLimit(config.HTTPMaxDownload)},
+		{"FTP max download", formatLimit(config.FTPMaxDownload)},
+		{"NZB max download", formatLimit(config.NZBMaxDownload)},
+		{"eMule", formatSwitch(config.EmuleEnabled)},
+		{"eMule max download", formatLimit(config.EmuleMaxDownload)},
+		{"eMule max upload", formatLimit(config.EmuleMaxUpload)},
+		{"eMule default destination", config.EmuleDefaultDestination},
+		{"Unzip service", formatSwitch(config.UnzipServiceEnabled)},
+	})
+}
+
+type DsConfigSet struct {
+	Logging
+	SynoClient              `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	BTMaxDownload           *rateLimit `long:"bt-max-download" env:"BT_MAX_DOWNLOAD" description:"BitTorrent download limit (ex: 5M, 512K, 0 - unlimited)"`
+	BTMaxUpload             *rateLimit `long:"bt-max-upload" env:"BT_MAX_UPLOAD" description:"BitTorrent upload limit"`
+	HTTPMaxDownload         *rateLimit `long:"http-max-download" env:"HTTP_MAX_DOWNLOAD" description:"HTTP download limit"`
+	FTPMaxDownload          *rateLimit `long:"ftp-max-download" env:"FTP_MAX_DOWNLOAD" description:"FTP download limit"`
+	NZBMaxDownload          *rateLimit `long:"nzb-max-download" env:"NZB_MAX_DOWNLOAD" description:"NZB download limit"`
+	EmuleMaxDownload        *rateLimit `long:"emule-max-download" env:"EMULE_MAX_DOWNLOAD" description:"eMule download limit"`
+	EmuleMaxUpload          *rateLimit `long:"emule-max-upload" env:"EMULE_MAX_UPLOAD" description:"eMule upload limit"`
+	Emule                   *string    `long:"emule" env:"EMULE" description:"Enable or disable eMule" choice:"on" choice:"off"`
+	Unzip                   *string    `long:"unzip" env:"UNZIP" description:"Enable or disable unzip service" choice:"on" choice:"off"`
+	DefaultDestination      *string    `long:"default-destination" env:"DEFAULT_DESTINATION" description:"Default destination, path starting with shared folder"`
+	EmuleDefaultDestination *string    `long:"emule-default-destination" env:"EMULE_DEFAULT_DESTINATION" description:"Default eMule destination"`
+}
+
+func (cmd *DsConfigSet) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	update := client.StationConfigUpdate{
+		BTMaxDownload:           cmd.BTMaxDownload.ptr(),
+		BTMaxUpload:             cmd.BTMaxUpload.ptr(),
+		EmuleMaxDownload:        cmd.EmuleMaxDownload.ptr(),
+		EmuleMaxUpload:          cmd.EmuleMaxUpload.ptr(),
+		NZBMaxDownload:          cmd.NZBMaxDownload.ptr(),
+		HTTPMaxDownload:         cmd.HTTPMaxDownload.ptr(),
+		FTPMaxDownload:          cmd.FTPMaxDownload.ptr(),
+		EmuleEnabled:            switchValue(cmd.Emule),
+		UnzipServiceEnabled:     switchValue(cmd.Unzip),
+		DefaultDestination:      cmd.DefaultDestination,
+		EmuleDefaultDestination: cmd.EmuleDefaultDestination,
+	}
+	if update.IsEmpty() {
+		return fmt.Errorf("nothing to change, set at least one option") //nolint:goerr113
+	}
+	if err := cmd.Client().DownloadStation().SetConfig(ctx, update); err != nil {
+		return fmt.Errorf("set config: %w", err)
+	}
+	return nil
+}
+
+type DsLimit struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Down       *rateLimit `long:"down" env:"DOWN" description:"Download limit for all protocols (ex: 5M, 512K)"`
+	Up         *rateLimit `long:"up" env:"UP" description:"Upload limit for BitTorrent and eMule (ex: 1M)"`
+	Off        bool       `long:"off" env:"OFF" description:"Remove all limits"`
+}
+
+func (cmd *DsLimit) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	down, up := cmd.Down.ptr(), cmd.Up.ptr()
+	if cmd.Off {
+		if down != nil || up != nil {
+			return fmt.Errorf("--off could not be used with --down or --up") //nolint:goerr113
+		}
+		var unlimited int64
+		down, up = &unlimited, &unlimited
+	}
+
+	ds := cmd.Client().DownloadStation()
+	update := client.StationConfigUpdate{
+		BTMaxDownload:    down,
+		EmuleMaxDownload: down,
+		NZBMaxDownload:   down,
+		HTTPMaxDownload:  down,
+		FTPMaxDownload:   down,
+		BTMaxUpload:      up,
+		EmuleMaxUpload:   up,
+	}
+	if err := ds.SetConfig(ctx, update); err != nil {
+		return fmt.Errorf("set limits: %w", err)
+	}
+
+	config, err := ds.Config(ctx)
+	if err != nil {
+		return fmt.Errorf("get config: %w", err)
+	}
+	return showKeyValues([][2]string{
+		{"BT", "down " + formatLimit(config.BTMaxDownload) + ", up " + formatLimit(config.BTMaxUpload)},
+		{"eMule", "down " + formatLimit(config.EmuleMaxDownload) + ", up " + formatLimit(config.EmuleMaxUpload)},
+		{"HTTP", "down " + formatLimit(config.HTTPMaxDownload)},
+		{"FTP", "down " + formatLimit(config.FTPMaxDownload)},
+		{"NZB", "down " + formatLimit(config.NZBMaxDownload)},
+	})
+}
+
+type DsScheduleGet struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Format     string `short:"f" long:"format" env:"FORMAT" description:"How to show output" default:"table" choice:"table" choice:"json"`
+}
+
+func (cmd *DsScheduleGet) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	schedule, err := cmd.Client().DownloadStation().Schedule(ctx)
+	if err != nil {
+		return fmt.Errorf("get schedule: %w", err)
+	}
+	if cmd.Format == fmtJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(schedule)
+	}
+	return showKeyValues([][2]string{
+		{"Schedule", formatSwitch(schedule.Enabled)},
+		{"eMule schedule", formatSwitch(schedule.EmuleEnabled)},
+	})
+}
+
+type DsScheduleSet struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Enabled    *string `long:"enabled" env:"ENABLED" description:"Enable or disable schedule" choice:"on" choice:"off"`
+	Emule      *string `long:"emule" env:"EMULE" description:"Enable or disable eMule schedule" choice:"on" choice:"off"`
+}
+
+func (cmd *DsScheduleSet) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	if cmd.Enabled == nil && cmd.Emule == nil {
+		return fmt.Errorf("nothing to change, set --enabled or --emule") //nolint:goerr113
+	}
+	ds := cmd.Client().DownloadStation()
+	// API sets both flags at once, so keep current value of not changed flag
+	schedule, err := ds.Schedule(ctx)
+	if err != nil {
+		return fmt.Errorf("get schedule: %w", err)
+	}
+	if v := switchValue(cmd.Enabled); v != nil {
+		schedule.Enabled = *v
+	}
+	if v := switchValue(cmd.Emule); v != nil {
+		schedule.EmuleEnabled = *v
+	}
+	if err := ds.SetSchedule(ctx, *schedule); err != nil {
+		return fmt.Errorf("set schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDsConfigSet_env(t *testing.T) {
+	t.Setenv("SYNOLOGY_USER", "admin")
+	t.Setenv("SYNOLOGY_PASSWORD", "admin")
+	t.Setenv("DEFAULT_DESTINATION", "video")
+	t.Setenv("BT_MAX_UPLOAD", "1M")
+	t.Setenv("EMULE", "off")
+
+	var cmd DsConfigSet
+	_, err := flags.NewParser(&cmd, flags.None).ParseArgs(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cmd.DefaultDestination)
+	assert.Equal(t, "video", *cmd.DefaultDestination)
+	require.NotNil(t, cmd.BTMaxUpload)
+	require.NotNil(t, cmd.Emule)
+	assert.Equal(t, "off", *cmd.Emule)
+	assert.Nil(t, cmd.BTMaxDownload, "unset options are not changed")
+	assert.Nil(t, cmd.Unzip)
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+// TaskSelector selects download tasks by IDs, title globs and status.
+type TaskSelector struct {
+	Status []client.TaskStatus `short:"s" long:"status" env:"STATUS" env-delim:"," description:"Select only tasks with status (ex: paused, finished, error). Without refs selects all tasks with status"`
+	Args   struct {
+		Refs []string `positional-arg-name:"ref" description:"Task ID or title glob (ex: *.iso)"`
+	} `positional-args:"yes"`
+}
+
+// selectTasks returns tasks matched by any ref and by status. At least one ref or status required.
+func (sel *TaskSelector) selectTasks(ctx context.Context, ds *client.DownloadStation) ([]client.ScheduledTask, error) {
+	if len(sel.Args.Refs) == 0 && len(sel.Status) == 0 {
+		return nil, fmt.Errorf("task ID, title glob or status should be set") //nolint:goerr113
+	}
+	for _, ref := range sel.Args.Refs {
+		if _, err := path.Match(ref, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", ref, err)
+		}
+	}
+	tasks, err := ds.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	var ans []client.ScheduledTask
+	for _, task := range tasks {
+		if sel.matches(task) {
+			ans = append(ans, task)
+		}
+	}
+	return ans, nil
+}
+
+// filters converts selector to task filters: task is selected if it matches any filter or its ID is one of refs.
+func (sel *TaskSelector) filters() []client.TaskFilter {
+	if len(sel.Args.Refs) == 0 {
+		return []client.TaskFilter{{Status: sel.Status}}
+	}
+	var ans = make([]client.TaskFilter, 0, len(sel.Args.Refs))
+	for _, ref := range sel.Args.Refs {
+		ans = append(ans, client.TaskFilter{Status: sel.Status, Title: ref})
+	}
+	return ans
+}
+
+func (sel *TaskSelector) matches(task client.ScheduledTask) bool {
+	for _, filter := range sel.filters() {
+		if filter.Match(&task) {
+			return true
+		}
+	}
+	byStatus := client.TaskFilter{Status: sel.Status}
+	return slices.Contains(sel.Args.Refs, task.ID) && byStatus.Match(&task)
+}
+
+// runTaskBatch selects tasks, applies operation and shows per-task results.
+func runTaskBatch(ctx context.Context, sel *TaskSelector, ds *client.DownloadStation, op func(ids []string) ([]client.TaskResult, error)) error {
+	tasks, err := sel.selectTasks(ctx, ds)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		slog.Info("no tasks matched")
+		return nil
+	}
+	var ids = make([]string, 0, len(tasks))
+	var titles = make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+		titles[task.ID] = task.Title
+	}
+	results, err := op(ids)
+	if err != nil {
+		return err
+	}
+	return showTaskResults(results, titles)
+}
+
+//nolint:gomnd
+func showTaskResults(results []client.TaskResult, titles map[string]string) error {
+	var failed int
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"ID", "\t",
+		"Result", "\t",
+		"Title", "\t",
+	)
+	for _, res := range results {
+		result := "ok"
+		if err := res.Err(); err != nil {
+			result = err.Error()
+			failed++
+		}
+		_, _ = fmt.Fprintln(tw,
+			res.ID, "\t",
+			result, "\t",
+			titles[res.ID], "\t",
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d task(s) failed", failed, len(results)) //nolint:goerr113
+	}
+	return nil
+}
+
+type DsPause struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	TaskSelector
+}
+
+func (cmd *DsPause) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	ds := cmd.Client().DownloadStation()
+	return runTaskBatch(ctx, &cmd.TaskSelector, ds, func(ids []string) ([]client.TaskResult, error) {
+		return ds.Pause(ctx, ids...)
+	})
+}
+
+type DsResume struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	TaskSelector
+}
+
+func (cmd *DsResume) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	ds := cmd.Client().DownloadStation()
+	return runTaskBatch(ctx, &cmd.TaskSelector, ds, func(ids []string) ([]client.TaskResult, error) {
+		return ds.Resume(ctx, ids...)
+	})
+}
+
+type DsRemove struct {
+	Logging
+	SynoClient    `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	ForceComplete bool `long:"force-complete" env:"FORCE_COMPLETE" description:"Move uncompleted downloaded files to destination instead of removing them"`
+	TaskSelector
+}
+
+func (cmd *DsRemove) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	ds := cmd.Client().DownloadStation()
+	return runTaskBatch(ctx, &cmd.TaskSelector, ds, func(ids []string) ([]client.TaskResult, error) {
+		return ds.Delete(ctx, cmd.ForceComplete, ids...)
+	})
+}
+
+type DsMove struct {
+	Logging
+	SynoClient  `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Destination string `short:"d" long:"destination" env:"DESTINATION" description:"New destination directory starting with shared folder" required:"true"`
+	TaskSelector
+}
+
+func (cmd *DsMove) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	ds := cmd.Client().DownloadStation()
+	return runTaskBatch(ctx, &cmd.TaskSelector, ds, func(ids []string) ([]client.TaskResult, error) {
+		return ds.Edit(ctx, cmd.Destination, ids...)
+	})
+}
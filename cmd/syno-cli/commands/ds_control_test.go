@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestTaskSelector_matches(t *testing.T) {
+	task := client.ScheduledTask{ID: "dbid_1", Title: "ubuntu.iso", Status: client.TaskStatusPaused}
+
+	tests := []struct {
+		name    string
+		refs    []string
+		status  []client.TaskStatus
+		matched bool
+	}{
+		{name: "status only", status: []client.TaskStatus{client.TaskStatusPaused, client.TaskStatusError}, matched: true},
+		{name: "other status", status: []client.TaskStatus{client.TaskStatusFinished}},
+		{name: "id", refs: []string{"dbid_2", "dbid_1"}, matched: true},
+		{name: "glob", refs: []string{"*.mkv", "*.iso"}, matched: true},
+		{name: "no ref matched", refs: []string{"dbid_2", "*.mkv"}},
+		{name: "id with status", refs: []string{"dbid_1"}, status: []client.TaskStatus{client.TaskStatusPaused}, matched: true},
+		{name: "id with other status", refs: []string{"dbid_1"}, status: []client.TaskStatus{client.TaskStatusFinished}},
+		{name: "glob with other status", refs: []string{"*.iso"}, status: []client.TaskStatus{client.TaskStatusFinished}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := TaskSelector{Status: tt.status}
+			sel.Args.Refs = tt.refs
+			assert.Equal(t, tt.matched, sel.matches(task))
+		})
+	}
+}
@@ -1,59 +1,333 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/reddec/syno-cli/pkg/client"
 )
 
 type DsCreate struct {
 	Logging
-	SynoClient  `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
-	Format      client.FileType `short:"f" long:"format" env:"FORMAT" description:"File format" default:"auto" choice:"torrent" choice:"txt" choice:"nzb" choice:"auto"`
-	Destination string          `short:"d" long:"destination" env:"DESTINATION" description:"Destination directory" default:"Downloads"`
-	Args        struct {
-		Ref string `positional-arg-name:"ref" description:"URL or file name. If not set or set to - (dash) - STDIN will be used"`
+	SynoClient   `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Format       client.FileType `short:"f" long:"format" env:"FORMAT" description:"File format" default:"auto" choice:"torrent" choice:"txt" choice:"nzb" choice:"auto"`
+	Destination  string          `short:"d" long:"destination" env:"DESTINATION" description:"Destination directory" default:"Downloads"`
+	Select       []string        `long:"select" env:"SELECT" env-delim:"," description:"Download only matched files of torrent: file index or glob by path or name (ex: *.mkv). Can be repeated"`
+	FromFile     []string        `short:"F" long:"from-file" env:"FROM_FILE" env-delim:"," description:"File with URLs, one per line (empty lines and lines started by # are ignored). - (dash) means STDIN. Can be repeated"`
+	Recursive    bool            `short:"R" long:"recursive" env:"RECURSIVE" description:"Scan directories for .torrent, .nzb and .txt files recursively"`
+	Concurrency  int             `short:"j" long:"concurrency" env:"CONCURRENCY" description:"Maximum number of tasks created in parallel" default:"4"`
+	MoveTo       string          `long:"move-to" env:"MOVE_TO" description:"Move source file to the directory after task created"`
+	RemoveSource bool            `long:"remove-source" env:"REMOVE_SOURCE" description:"Remove source file after task created"`
+	Wait         bool            `short:"w" long:"wait" env:"WAIT" description:"Wait till created tasks are finished or seeding"`
+	TaskWaiter
+	Args struct {
+		Refs []string `positional-arg-name:"ref" description:"URL, file name, glob (ex: *.torrent) or directory (with -R). If not set or set to - (dash) - STDIN will be used"`
 	} `positional-args:"yes"`
 }
 
+// createItem is single source of download task.
+type createItem struct {
+	Ref   string // URL, path to file or - (dash) for STDIN
+	URL   bool
+	Local bool  // local file which could be moved or removed
+	Err   error // error during collecting, item will not be created
+}
+
+// createResult is outcome of single item creation.
+type createResult struct {
+	Item createItem
+	IDs  []string
+	Err  error
+}
+
 func (cmd *DsCreate) Execute([]string) error {
 	cmd.SetupLogging()
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
+	if cmd.MoveTo != "" && cmd.RemoveSource {
+		return fmt.Errorf("only one of --move-to and --remove-source could be set") //nolint:goerr113
+	}
+	if cmd.Concurrency <= 0 {
+		return fmt.Errorf("concurrency should be positive") //nolint:goerr113
+	}
+	items, err := cmd.collect()
+	if err != nil {
+		return err
+	}
+
+	ds := cmd.Client().DownloadStation()
+	results := cmd.createAll(ctx, ds, items)
+
+	var ids []string
+	var failed int
+	for _, res := range results {
+		ids = append(ids, res.IDs...)
+		if res.Err != nil {
+			failed++
+		}
+	}
+
+	if len(results) == 1 {
+		// single task: keep output script-friendly
+		if err := results[0].Err; err != nil {
+			return err
+		}
+		if !cmd.Wait {
+			for _, id := range ids {
+				fmt.Println(id)
+			}
+		}
+	} else if err := showCreateResults(results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d item(s) failed", failed, len(results)) //nolint:goerr113
+	}
+	if !cmd.Wait {
+		return nil
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("created tasks not found, nothing to wait") //nolint:goerr113
+	}
+	return cmd.wait(ctx, ds, ids)
+}
+
+// collect items from refs and URL lists. Invalid refs are kept as failed items.
+func (cmd *DsCreate) collect() ([]createItem, error) {
+	var items []createItem
+	for _, listFile := range cmd.FromFile {
+		urls, err := readURLList(listFile)
+		if err != nil {
+			return nil, fmt.Errorf("read URLs from %s: %w", listFile, err)
+		}
+		for _, u := range urls {
+			items = append(items, createItem{Ref: u, URL: true})
+		}
+	}
+
+	refs := cmd.Args.Refs
+	if len(refs) == 0 && len(cmd.FromFile) == 0 {
+		refs = []string{"-"}
+	}
+	for _, ref := range refs {
+		items = append(items, cmd.expandRef(ref)...)
+	}
+	var seen = make(map[string]bool, len(items))
+	items = slices.DeleteFunc(items, func(item createItem) bool {
+		duplicate := seen[item.Ref] && item.Ref != "-"
+		seen[item.Ref] = true
+		return duplicate
+	})
+	if len(items) == 0 {
+		return nil, fmt.Errorf("nothing to create") //nolint:goerr113
+	}
+	return items, nil
+}
+
+func (cmd *DsCreate) expandRef(ref string) []createItem {
+	if ref == "-" {
+		return []createItem{{Ref: ref}}
+	}
+	if u, err := url.Parse(ref); err == nil && len(u.Scheme) > 1 { // single letter is Windows drive
+		return []createItem{{Ref: ref, URL: true}}
+	}
+	paths := []string{ref}
+	if hasGlobMeta(ref) {
+		matches, err := filepath.Glob(ref)
+		if err != nil {
+			return []createItem{{Ref: ref, Err: err}}
+		}
+		if len(matches) == 0 {
+			return []createItem{{Ref: ref, Err: fmt.Errorf("no files matched")}} //nolint:goerr113
+		}
+		paths = matches
+	}
+
+	var items []createItem
+	for _, p := range paths {
+		stat, err := os.Stat(p)
+		switch {
+		case err != nil:
+			items = append(items, createItem{Ref: p, Err: err})
+		case !stat.IsDir():
+			items = append(items, createItem{Ref: p, Local: true})
+		case !cmd.Recursive:
+			items = append(items, createItem{Ref: p, Err: fmt.Errorf("is a directory, use --recursive")}) //nolint:goerr113
+		default:
+			files, err := scanTaskFiles(p, cmd.MoveTo)
+			if err != nil {
+				items = append(items, createItem{Ref: p, Err: err})
+			}
+			for _, file := range files {
+				items = append(items, createItem{Ref: file, Local: true})
+			}
+		}
+	}
+	return items
+}
+
+// createAll creates tasks for items with bounded concurrency. Results are in the same order as items.
+func (cmd *DsCreate) createAll(ctx context.Context, ds *client.DownloadStation, items []createItem) []createResult {
+	results := make([]createResult, len(items))
+	semaphore := make(chan struct{}, cmd.Concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		results[i].Item = item
+		if item.Err != nil {
+			results[i].Err = item.Err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+			defer func() { <-semaphore }()
+			results[i].IDs, results[i].Err = cmd.createOne(ctx, ds, item)
+			if results[i].Err != nil {
+				slog.Error("failed create task", "ref", item.Ref, "error", results[i].Err)
+				return
+			}
+			slog.Info("created task in Download Station", "ref", item.Ref, "ids", results[i].IDs)
+			if err := cmd.cleanupSource(item); err != nil {
+				slog.Warn("failed to clean up source", "ref", item.Ref, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (cmd *DsCreate) createOne(ctx context.Context, ds *client.DownloadStation, item createItem) ([]string, error) {
 	params := client.DownloadTask{
 		FileType:    cmd.Format,
 		Destination: cmd.Destination,
 	}
-
-	syno := cmd.Client()
-
-	if u, err := url.Parse(cmd.Args.Ref); err == nil && u.Scheme != "" {
-		slog.Debug("ref is URL", "url", u.Redacted())
-		params.URL = []string{cmd.Args.Ref}
-	} else if cmd.Args.Ref == "" || cmd.Args.Ref == "-" {
-		slog.Debug("ref is STDIN payload")
-		params.File = os.Stdin
+	if item.URL {
+		slog.Debug("ref is URL", "ref", item.Ref)
+		if len(cmd.Select) > 0 {
+			return nil, fmt.Errorf("files selection supported only for torrent files") //nolint:goerr113
+		}
+		if strings.HasPrefix(item.Ref, "magnet:") {
+			info, err := client.ParseMagnet(item.Ref)
+			if err != nil {
+				return nil, err
+			}
+			slog.Info("magnet link", "name", info.Name, "info_hash", info.InfoHash, "size", info.Size, "trackers", len(info.Trackers))
+		}
+		params.URL = []string{item.Ref}
 	} else {
-		slog.Debug("ref is file")
-		f, err := os.Open(cmd.Args.Ref)
+		var data []byte
+		var err error
+		if item.Ref == "-" {
+			slog.Debug("ref is STDIN payload")
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			slog.Debug("ref is file", "ref", item.Ref)
+			data, err = os.ReadFile(item.Ref)
+		}
 		if err != nil {
-			return fmt.Errorf("open file: %w", err)
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		if err := cmd.inspect(&params, data); err != nil {
+			return nil, err
 		}
-		defer f.Close()
-		params.File = f
+		params.File = bytes.NewReader(data)
 	}
 	slog.Debug("creating download task", "destination", params.Destination)
-	err := syno.DownloadStation().Create(ctx, params)
+	return ds.Create(ctx, params)
+}
+
+// cleanupSource moves or removes local source file if requested.
+func (cmd *DsCreate) cleanupSource(item createItem) error {
+	if !item.Local {
+		return nil
+	}
+	if cmd.RemoveSource {
+		return os.Remove(item.Ref)
+	}
+	if cmd.MoveTo != "" {
+		_, err := moveFile(item.Ref, cmd.MoveTo)
+		return err
+	}
+	return nil
+}
+
+//nolint:gomnd
+func showCreateResults(results []createResult) error {
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"Ref", "\t",
+		"Result", "\t",
+		"Tasks", "\t",
+	)
+	for _, res := range results {
+		result := "ok"
+		if res.Err != nil {
+			result = res.Err.Error()
+		}
+		_, _ = fmt.Fprintln(tw,
+			res.Item.Ref, "\t",
+			result, "\t",
+			strings.Join(res.IDs, ","), "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+// inspect detects file type, validates torrent and selects files in it.
+func (cmd *DsCreate) inspect(params *client.DownloadTask, data []byte) error {
+	return inspectFile(params, data, cmd.Select)
+}
+
+// inspectFile detects file type (if not set), validates torrent and selects files in it by selectors.
+func inspectFile(params *client.DownloadTask, data []byte, selectors []string) error {
+	if params.FileType == client.FileTypeAuto || params.FileType == client.FileTypeUnknown {
+		ft, err := client.DetectFileType(data)
+		if err != nil {
+			return fmt.Errorf("detect file type: %w", err)
+		}
+		slog.Debug("filetype automatically detected", "filetype", ft)
+		params.FileType = ft
+	}
+	if params.FileType != client.FileTypeTorrent {
+		if len(selectors) > 0 {
+			return fmt.Errorf("files selection supported only for torrent files") //nolint:goerr113
+		}
+		return nil
+	}
+	info, err := client.ParseTorrent(data)
 	if err != nil {
 		return err
 	}
-	slog.Info("created task in Download Station")
+	slog.Info("torrent", "name", info.Name, "info_hash", info.InfoHash, "size", humanBytes(info.Size), "files", len(info.Files))
+	if len(selectors) == 0 {
+		return nil
+	}
+	params.Select, err = info.Select(selectors...)
+	if err != nil {
+		return fmt.Errorf("select files: %w", err)
+	}
+	for _, idx := range params.Select {
+		slog.Info("file selected", "index", idx, "path", info.Files[idx].Path, "size", humanBytes(info.Files[idx].Size))
+	}
 	return nil
 }
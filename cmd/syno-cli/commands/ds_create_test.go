@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const testTorrent = "d8:announce17:udp://tracker/one4:infod6:lengthi42e4:name8:disk.iso" +
+	"12:piece lengthi16384e6:pieces20:bbbbbbbbbbbbbbbbbbbbee"
+
+func TestDsCreate_createAll(t *testing.T) {
+	dir := t.TempDir()
+	torrent := filepath.Join(dir, "disk.torrent")
+	require.NoError(t, os.WriteFile(torrent, []byte(testTorrent), 0600))
+
+	var lock sync.Mutex
+	var inFlight, maxInFlight int
+	syno := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		if request.FormValue("api") == "SYNO.DownloadStation.Task" && request.FormValue("method") == "list" {
+			return client.DownloadTasks{} // snapshot before the first creation
+		}
+		assert.Equal(t, "SYNO.DownloadStation2.Task", request.FormValue("api"))
+		assert.Equal(t, "create", request.FormValue("method"))
+		lock.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		lock.Unlock()
+		time.Sleep(100 * time.Millisecond) // let other creations start
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+
+		id := "dbid_file"
+		if request.FormValue("type") == `"url"` {
+			var urls []string
+			assert.NoError(t, json.Unmarshal([]byte(request.FormValue("url")), &urls))
+			id = "dbid_" + path.Base(urls[0])
+		}
+		return map[string]any{"task_id": []string{id}}
+	})
+
+	cmd := &DsCreate{SynoClient: syno, Destination: "Downloads", Concurrency: 4}
+	items := []createItem{
+		{Ref: "https://example.com/a.iso", URL: true},
+		{Ref: torrent, Local: true},
+		{Ref: "https://example.com/b.iso", URL: true},
+		{Ref: "missing.torrent", Err: os.ErrNotExist},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	results := cmd.createAll(ctx, cmd.Client().DownloadStation(), items)
+
+	require.Len(t, results, len(items))
+	require.Equal(t, []string{"dbid_a.iso"}, results[0].IDs)
+	require.Equal(t, []string{"dbid_file"}, results[1].IDs)
+	require.Equal(t, []string{"dbid_b.iso"}, results[2].IDs)
+	require.ErrorIs(t, results[3].Err, os.ErrNotExist)
+	for _, res := range results[:3] {
+		require.NoError(t, res.Err)
+	}
+	require.Equal(t, 2, maxInFlight, "items should be created in parallel once Synology returned IDs of the first one")
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+// taskFileTypes are extensions of files which could be uploaded as download tasks.
+//
+//nolint:gochecknoglobals
+var taskFileTypes = map[string]client.FileType{
+	".torrent": client.FileTypeTorrent,
+	".nzb":     client.FileTypeNzb,
+	".txt":     client.FileTypeTxt,
+}
+
+// taskFileType returns file type by extension or unknown type for unsupported files.
+func taskFileType(name string) client.FileType {
+	return taskFileTypes[strings.ToLower(filepath.Ext(name))]
+}
+
+func isTaskFile(name string) bool {
+	return taskFileType(name) != client.FileTypeUnknown
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[`)
+}
+
+// scanTaskFiles returns all task files (see isTaskFile) in directory and its subdirectories except skipped.
+func scanTaskFiles(dir string, skipDirs ...string) ([]string, error) {
+	var skip = make(map[string]bool, len(skipDirs))
+	for _, skipDir := range skipDirs {
+		if abs, err := filepath.Abs(skipDir); err == nil {
+			skip[abs] = true
+		}
+	}
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && len(skip) > 0 {
+			if abs, err := filepath.Abs(path); err == nil && skip[abs] {
+				return filepath.SkipDir
+			}
+		}
+		if d.Type().IsRegular() && isTaskFile(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readURLList reads URLs from file, one per line. Empty lines and comments (#) are ignored. Dash means STDIN.
+func readURLList(file string) ([]string, error) {
+	var src io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+	var urls []string
+	scanner := bufio.NewScanner(src)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if u, err := url.Parse(line); err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("line %d: not an URL: %q", n, line) //nolint:goerr113
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+// moveFile moves file to directory (created if needed) and returns new path. If file with the same name already
+// exists, timestamp is added to the name. Files on different devices are copied and then removed.
+func moveFile(src string, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gomnd
+		return "", fmt.Errorf("create directory: %w", err)
+	}
+	name := filepath.Base(src)
+	dst := filepath.Join(dir, name)
+	if _, err := os.Stat(dst); err == nil {
+		ext := filepath.Ext(name)
+		dst = filepath.Join(dir, strings.TrimSuffix(name, ext)+"."+strconv.FormatInt(time.Now().UnixNano(), 10)+ext)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return dst, nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return "", err
+	}
+	return dst, os.Remove(src)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644) //nolint:gomnd
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+// humanBytes formats size in binary units (ex: 1.5 GiB).
+func humanBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// humanSpeed formats speed in bytes per second. Zero speed is shown as dash.
+func humanSpeed(speed int64) string {
+	if speed <= 0 {
+		return "-"
+	}
+	return humanBytes(speed) + "/s"
+}
+
+func formatProgress(task *client.ScheduledTask) string {
+	return fmt.Sprintf("%.1f%%", task.Progress()*100) //nolint:gomnd
+}
+
+func formatETA(task *client.ScheduledTask) string {
+	eta, ok := task.ETA()
+	if !ok {
+		return "-"
+	}
+	return eta.Round(time.Second).String()
+}
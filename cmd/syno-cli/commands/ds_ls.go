@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path"
+	"slices"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -15,9 +18,15 @@ import (
 type DsList struct {
 	Logging
 	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
-	Format     string `short:"f" long:"format" env:"FORMAT" description:"How to show output" default:"table" choice:"table" choice:"json"`
-	Offset     int    `short:"o" long:"offset" env:"OFFSET" description:"Offset" default:"0"`
-	Limit      int    `short:"l" long:"limit" env:"LIMIT" description:"Max number of items" default:"1000"`
+	Format     string              `short:"f" long:"format" env:"FORMAT" description:"How to show output" default:"table" choice:"table" choice:"json"`
+	Offset     int                 `short:"o" long:"offset" env:"OFFSET" description:"Offset" default:"0"`
+	Limit      int                 `short:"l" long:"limit" env:"LIMIT" description:"Max number of items. If not set, all tasks are fetched page by page"`
+	Status     []client.TaskStatus `short:"s" long:"status" env:"STATUS" env-delim:"," description:"Show only tasks with status (ex: downloading, seeding, error)"`
+	Type       []client.TaskType   `short:"t" long:"type" env:"TYPE" env-delim:"," description:"Show only tasks with type (ex: bt, https)"`
+	User       []string            `short:"u" long:"user" env:"USER_FILTER" env-delim:"," description:"Show only tasks created by user"`
+	Title      string              `long:"title" env:"TITLE" description:"Show only tasks with title matched glob (ex: *.iso)"`
+	Sort       string              `long:"sort" env:"SORT" description:"Sort tasks by field" choice:"id" choice:"title" choice:"status" choice:"type" choice:"user" choice:"size" choice:"progress" choice:"speed" choice:"created"`
+	Reverse    bool                `short:"r" long:"reverse" env:"REVERSE" description:"Reverse sort order"`
 }
 
 func (cmd *DsList) Execute([]string) error {
@@ -26,12 +35,101 @@ func (cmd *DsList) Execute([]string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	syno := cmd.Client()
-	info, err := syno.DownloadStation().List(ctx, cmd.Offset, cmd.Limit)
+	if _, err := path.Match(cmd.Title, ""); err != nil {
+		return fmt.Errorf("invalid title glob: %w", err)
+	}
+	tasks, err := cmd.fetch(ctx, cmd.Client().DownloadStation())
 	if err != nil {
 		return fmt.Errorf("list tasks: %w", err)
 	}
-	return cmd.show(info.Tasks)
+	filter := client.TaskFilter{Status: cmd.Status, Type: cmd.Type, User: cmd.User, Title: cmd.Title}
+	var list = make([]client.ScheduledTask, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.Match(&task) {
+			list = append(list, task)
+		}
+	}
+	sortTasks(list, cmd.Sort, cmd.Reverse)
+	return cmd.show(list)
+}
+
+// fetch single page if limit set, otherwise all tasks after offset.
+func (cmd *DsList) fetch(ctx context.Context, ds *client.DownloadStation) ([]client.ScheduledTask, error) {
+	if cmd.Limit > 0 {
+		info, err := ds.List(ctx, cmd.Offset, cmd.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return info.Tasks, nil
+	}
+	var ans []client.ScheduledTask
+	var skip = cmd.Offset
+	for task, err := range ds.Tasks(ctx, client.DefaultPageSize) {
+		if err != nil {
+			return nil, err
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		ans = append(ans, task)
+	}
+	return ans, nil
+}
+
+// sortTasks sorts tasks in place by field. Empty field keeps original order (unless reversed).
+func sortTasks(list []client.ScheduledTask, field string, reverse bool) {
+	var cmp func(a, b *client.ScheduledTask) int
+	switch field {
+	case "id":
+		cmp = func(a, b *client.ScheduledTask) int { return strings.Compare(a.ID, b.ID) }
+	case "title":
+		cmp = func(a, b *client.ScheduledTask) int { return strings.Compare(a.Title, b.Title) }
+	case "status":
+		cmp = func(a, b *client.ScheduledTask) int { return strings.Compare(string(a.Status), string(b.Status)) }
+	case "type":
+		cmp = func(a, b *client.ScheduledTask) int { return strings.Compare(string(a.Type), string(b.Type)) }
+	case "user":
+		cmp = func(a, b *client.ScheduledTask) int { return strings.Compare(a.Username, b.Username) }
+	case "size":
+		cmp = func(a, b *client.ScheduledTask) int { return cmpInt(a.Size, b.Size) }
+	case "progress":
+		cmp = func(a, b *client.ScheduledTask) int { return cmpFloat(a.Progress(), b.Progress()) }
+	case "speed":
+		cmp = func(a, b *client.ScheduledTask) int {
+			return cmpInt(a.Additional.Transfer.SpeedDownload, b.Additional.Transfer.SpeedDownload)
+		}
+	case "created":
+		cmp = func(a, b *client.ScheduledTask) int {
+			return cmpInt(a.Additional.Detail.CreateTime, b.Additional.Detail.CreateTime)
+		}
+	}
+	if cmp != nil {
+		slices.SortStableFunc(list, func(a, b client.ScheduledTask) int { return cmp(&a, &b) })
+	}
+	if reverse {
+		slices.Reverse(list)
+	}
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
 
 //nolint:gomnd
@@ -51,6 +149,10 @@ func (cmd *DsList) show(list []client.ScheduledTask) error {
 			"Status", "\t",
 			"Type", "\t",
 			"Size", "\t",
+			"Progress", "\t",
+			"Down", "\t",
+			"Up", "\t",
+			"ETA", "\t",
 			"Created", "\t",
 			"Title", "\t",
 		)
@@ -60,7 +162,11 @@ func (cmd *DsList) show(list []client.ScheduledTask) error {
 				item.Username, "\t",
 				item.Status, "\t",
 				item.Type, "\t",
-				item.Size, "\t",
+				humanBytes(item.Size), "\t",
+				formatProgress(&item), "\t",
+				humanSpeed(item.Additional.Transfer.SpeedDownload), "\t",
+				humanSpeed(item.Additional.Transfer.SpeedUpload), "\t",
+				formatETA(&item), "\t",
 				time.Unix(item.Additional.Detail.CreateTime, 0).Format(time.RFC3339), "\t",
 				item.Title,
 			)
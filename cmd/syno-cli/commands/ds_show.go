@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+type DsShow struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Format     string `short:"f" long:"format" env:"FORMAT" description:"How to show output" default:"table" choice:"table" choice:"json"`
+	Args       struct {
+		IDs []string `positional-arg-name:"id" description:"Task ID" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (cmd *DsShow) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	syno := cmd.Client()
+	tasks, err := syno.DownloadStation().Get(ctx, cmd.Args.IDs...)
+	if err != nil {
+		return fmt.Errorf("get tasks: %w", err)
+	}
+	return cmd.show(tasks)
+}
+
+//nolint:gomnd
+func (cmd *DsShow) show(tasks []client.ScheduledTask) error {
+	if cmd.Format == fmtJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+	}
+
+	for i, task := range tasks {
+		if i > 0 {
+			fmt.Println()
+		}
+		detail, transfer := task.Additional.Detail, task.Additional.Transfer
+		tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+		rows := [][2]string{
+			{"ID", task.ID},
+			{"Title", task.Title},
+			{"Status", task.Status.String()},
+			{"Type", task.Type.String()},
+			{"User", task.Username},
+			{"Destination", detail.Destination},
+			{"URI", detail.URI},
+			{"Created", time.Unix(detail.CreateTime, 0).Format(time.RFC3339)},
+			{"Priority", detail.Priority},
+			{"Size", humanBytes(task.Size)},
+			{"Downloaded", humanBytes(transfer.SizeDownloaded) + " (" + formatProgress(&task) + ")"},
+			{"Uploaded", humanBytes(transfer.SizeUploaded)},
+			{"Speed", "down " + humanSpeed(transfer.SpeedDownload) + ", up " + humanSpeed(transfer.SpeedUpload)},
+			{"ETA", formatETA(&task)},
+			{"Peers", fmt.Sprintf("%d (seeders %d, leechers %d)", detail.TotalPeers, detail.ConnectedSeeders, detail.ConnectedLeechers)},
+		}
+		for _, row := range rows {
+			_, _ = fmt.Fprintln(tw, row[0]+":", "\t", row[1])
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if err := showTaskFiles(task.Additional.File); err != nil {
+			return err
+		}
+		if err := showTaskTrackers(task.Additional.Tracker); err != nil {
+			return err
+		}
+		if err := showTaskPeers(task.Additional.Peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//nolint:gomnd
+func showTaskFiles(files []client.TaskFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	fmt.Println("\nFiles:")
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"#", "\t",
+		"Wanted", "\t",
+		"Priority", "\t",
+		"Size", "\t",
+		"Progress", "\t",
+		"Name", "\t",
+	)
+	for i, file := range files {
+		var progress float64
+		if file.Size > 0 {
+			progress = float64(file.SizeDownloaded) / float64(file.Size) * 100
+		}
+		_, _ = fmt.Fprintln(tw,
+			i, "\t",
+			file.Wanted(), "\t",
+			file.Priority, "\t",
+			humanBytes(file.Size), "\t",
+			fmt.Sprintf("%.1f%%", progress), "\t",
+			file.FileName, "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+//nolint:gomnd
+func showTaskTrackers(trackers []client.TaskTracker) error {
+	if len(trackers) == 0 {
+		return nil
+	}
+	fmt.Println("\nTrackers:")
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"URL", "\t",
+		"Status", "\t",
+		"Seeds", "\t",
+		"Peers", "\t",
+		"Next update", "\t",
+	)
+	for _, tracker := range trackers {
+		_, _ = fmt.Fprintln(tw,
+			tracker.URL, "\t",
+			tracker.Status, "\t",
+			tracker.Seeds, "\t",
+			tracker.Peers, "\t",
+			time.Duration(tracker.UpdateTimer)*time.Second, "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+//nolint:gomnd
+func showTaskPeers(peers []client.TaskPeer) error {
+	if len(peers) == 0 {
+		return nil
+	}
+	fmt.Println("\nPeers:")
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"Address", "\t",
+		"Agent", "\t",
+		"Progress", "\t",
+		"Down", "\t",
+		"Up", "\t",
+	)
+	for _, peer := range peers {
+		_, _ = fmt.Fprintln(tw,
+			peer.Address, "\t",
+			peer.Agent, "\t",
+			fmt.Sprintf("%.1f%%", peer.Progress*100), "\t",
+			humanSpeed(peer.SpeedDownload), "\t",
+			humanSpeed(peer.SpeedUpload), "\t",
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+// TaskWaiter waits for tasks completion.
+type TaskWaiter struct {
+	Timeout      time.Duration `long:"timeout" env:"TIMEOUT" description:"Maximum time to wait, 0 means no limit"`
+	PollInterval time.Duration `long:"poll-interval" env:"POLL_INTERVAL" description:"Interval between tasks status checks" default:"5s"`
+}
+
+// wait till tasks completed and shows their final state. Timeout, failed or removed task is an error.
+func (tw *TaskWaiter) wait(ctx context.Context, ds *client.DownloadStation, ids []string) error {
+	if tw.PollInterval <= 0 {
+		return fmt.Errorf("poll interval should be positive") //nolint:goerr113
+	}
+	if tw.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tw.Timeout)
+		defer cancel()
+	}
+	slog.Info("waiting for tasks", "ids", ids, "timeout", tw.Timeout)
+	tasks, err := ds.Wait(ctx, tw.PollInterval, ids...)
+	if len(tasks) > 0 {
+		if err := showWaitedTasks(tasks); err != nil {
+			return err
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("tasks not completed in %s: %w", tw.Timeout, err)
+	}
+	return err
+}
+
+//nolint:gomnd
+func showWaitedTasks(tasks []client.ScheduledTask) error {
+	tw := tabwriter.NewWriter(os.Stdout, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"ID", "\t",
+		"Status", "\t",
+		"Progress", "\t",
+		"Destination", "\t",
+		"Title", "\t",
+	)
+	for _, task := range tasks {
+		_, _ = fmt.Fprintln(tw,
+			task.ID, "\t",
+			task.Status, "\t",
+			formatProgress(&task), "\t",
+			task.Additional.Detail.Destination, "\t",
+			task.Title, "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+type DsWait struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	TaskWaiter
+	Args struct {
+		IDs []string `positional-arg-name:"id" description:"Task ID" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (cmd *DsWait) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	return cmd.wait(ctx, cmd.Client().DownloadStation(), cmd.Args.IDs)
+}
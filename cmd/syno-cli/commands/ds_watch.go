@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	watchAuto  = "auto"
+	watchTable = "table"
+	watchJSONL = "jsonl"
+
+	taskAdded         = "added"
+	taskStatusChanged = "status_changed"
+	taskCompleted     = "completed"
+	taskErrored       = "errored"
+	taskRemoved       = "removed"
+
+	progressBarWidth = 20
+)
+
+type DsWatch struct {
+	Logging
+	SynoClient `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Format     string              `short:"f" long:"format" env:"FORMAT" description:"Output: live table, JSONL events or auto (table for terminal, events otherwise)" default:"auto" choice:"auto" choice:"table" choice:"jsonl"`
+	Interval   time.Duration       `short:"i" long:"interval" env:"INTERVAL" description:"Poll interval" default:"5s"`
+	Status     []client.TaskStatus `short:"s" long:"status" env:"STATUS" env-delim:"," description:"Watch only tasks with status (ex: downloading, error)"`
+	Title      string              `long:"title" env:"TITLE" description:"Watch only tasks with title matched glob (ex: *.iso)"`
+}
+
+// taskEvent is change of download task between polls.
+type taskEvent struct {
+	Event      string            `json:"event"`
+	Time       time.Time         `json:"time"`
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Status     client.TaskStatus `json:"status"`
+	PrevStatus client.TaskStatus `json:"prev_status,omitempty"`
+	Size       int64             `json:"size"`
+	Progress   float64           `json:"progress"` // [0, 1]
+	Error      string            `json:"error,omitempty"`
+}
+
+func (cmd *DsWatch) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	if cmd.Interval <= 0 {
+		return fmt.Errorf("interval should be positive") //nolint:goerr113
+	}
+	if _, err := path.Match(cmd.Title, ""); err != nil {
+		return fmt.Errorf("invalid title glob: %w", err)
+	}
+
+	format := cmd.Format
+	if format == watchAuto {
+		format = watchJSONL
+		if isTerminal(os.Stdout) {
+			format = watchTable
+		}
+	}
+
+	ds := cmd.Client().DownloadStation()
+	filter := client.TaskFilter{Status: cmd.Status, Title: cmd.Title}
+	enc := json.NewEncoder(os.Stdout)
+	var prev map[string]client.ScheduledTask
+
+	ticker := time.NewTicker(cmd.Interval)
+	defer ticker.Stop()
+	for {
+		tasks, err := ds.All(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed list tasks", "error", err)
+		} else if err == nil {
+			tasks = filterTasks(tasks, &filter)
+			if format == watchTable {
+				if err := drawWatch(os.Stdout, tasks, cmd.Interval); err != nil {
+					return err
+				}
+			} else {
+				for _, event := range diffTasks(prev, tasks) {
+					if err := enc.Encode(event); err != nil {
+						return err
+					}
+				}
+			}
+			prev = make(map[string]client.ScheduledTask, len(tasks))
+			for _, task := range tasks {
+				prev[task.ID] = task
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func filterTasks(tasks []client.ScheduledTask, filter *client.TaskFilter) []client.ScheduledTask {
+	var ans = make([]client.ScheduledTask, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.Match(&task) {
+			ans = append(ans, task)
+		}
+	}
+	return ans
+}
+
+// diffTasks returns events between previous and current state. Nil previous state means the first poll:
+// all existing tasks are reported as added.
+func diffTasks(prev map[string]client.ScheduledTask, current []client.ScheduledTask) []taskEvent {
+	now := time.Now()
+	var events []taskEvent
+	var exists = make(map[string]bool, len(current))
+	for _, task := range current {
+		exists[task.ID] = true
+		old, known := prev[task.ID]
+		switch {
+		case !known:
+			events = append(events, newTaskEvent(now, taskAdded, &task))
+		case old.Status != task.Status:
+			event := newTaskEvent(now, taskStatusChanged, &task)
+			event.PrevStatus = old.Status
+			events = append(events, event)
+		default:
+			continue
+		}
+		if prev == nil {
+			continue // do not report completed and errored tasks existed before watch
+		}
+		switch {
+		case task.Status.Completed() && !(known && old.Status.Completed()):
+			events = append(events, newTaskEvent(now, taskCompleted, &task))
+		case task.Status == client.TaskStatusError:
+			events = append(events, newTaskEvent(now, taskErrored, &task))
+		}
+	}
+	for id, task := range prev {
+		if !exists[id] {
+			events = append(events, newTaskEvent(now, taskRemoved, &task))
+		}
+	}
+	return events
+}
+
+func newTaskEvent(now time.Time, event string, task *client.ScheduledTask) taskEvent {
+	var errorDetail string
+	if task.StatusExtra != nil {
+		errorDetail = task.StatusExtra.ErrorDetail
+	}
+	return taskEvent{
+		Event:    event,
+		Time:     now,
+		ID:       task.ID,
+		Title:    task.Title,
+		Status:   task.Status,
+		Size:     task.Size,
+		Progress: task.Progress(),
+		Error:    errorDetail,
+	}
+}
+
+// drawWatch clears terminal and draws tasks table with summary.
+//
+//nolint:gomnd
+func drawWatch(out io.Writer, tasks []client.ScheduledTask, interval time.Duration) error {
+	var down, up int64
+	for _, task := range tasks {
+		down += task.Additional.Transfer.SpeedDownload
+		up += task.Additional.Transfer.SpeedUpload
+	}
+	_, _ = fmt.Fprint(out, "\033[H\033[2J")
+	_, _ = fmt.Fprintf(out, "Every %s: %d task(s), down %s, up %s  %s\n\n", interval, len(tasks), humanSpeed(down), humanSpeed(up), time.Now().Format(time.TimeOnly))
+
+	tw := tabwriter.NewWriter(out, 3, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw,
+		"ID", "\t",
+		"Status", "\t",
+		"Progress", "\t",
+		"Size", "\t",
+		"Down", "\t",
+		"Up", "\t",
+		"ETA", "\t",
+		"Title", "\t",
+	)
+	for _, task := range tasks {
+		_, _ = fmt.Fprintln(tw,
+			task.ID, "\t",
+			task.Status, "\t",
+			progressBar(task.Progress(), progressBarWidth)+" "+formatProgress(&task), "\t",
+			humanBytes(task.Size), "\t",
+			humanSpeed(task.Additional.Transfer.SpeedDownload), "\t",
+			humanSpeed(task.Additional.Transfer.SpeedUpload), "\t",
+			formatETA(&task), "\t",
+			task.Title, "\t",
+		)
+	}
+	return tw.Flush()
+}
+
+// progressBar renders progress [0, 1] as bar of fixed width (ex: [#####-----]).
+func progressBar(progress float64, width int) string {
+	filled := int(progress * float64(width))
+	filled = max(0, min(width, filled))
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// isTerminal checks that file is character device (terminal), not pipe or regular file.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+type DsWatchDir struct {
+	Logging
+	SynoClient    `group:"Synology Client" namespace:"synology" env-namespace:"SYNOLOGY"`
+	Destination   string        `short:"d" long:"destination" env:"DESTINATION" description:"Destination for files in the root of watched directory. Files in subdirectories use relative path of subdirectory as destination" default:"Downloads"`
+	Done          string        `long:"done" env:"DONE" description:"Directory for processed files, relative to watched directory" default:"done"`
+	Failed        string        `long:"failed" env:"FAILED" description:"Directory for failed files, relative to watched directory" default:"failed"`
+	Settle        time.Duration `long:"settle" env:"SETTLE" description:"Wait till file is not changed for the duration before upload" default:"2s"`
+	Retries       int           `long:"retries" env:"RETRIES" description:"Number of retries on connection errors before file marked as failed" default:"3"`
+	RetryInterval time.Duration `long:"retry-interval" env:"RETRY_INTERVAL" description:"Interval between retries" default:"30s"`
+	Args          struct {
+		Dir string `positional-arg-name:"dir" description:"Directory to watch" required:"yes"`
+	} `positional-args:"yes"`
+
+	ds       *client.DownloadStation
+	root     string
+	watcher  *fsnotify.Watcher
+	lock     sync.Mutex
+	pending  map[string]*time.Timer // path -> scheduled processing
+	attempts map[string]int         // path -> failed attempts
+	ready    chan string
+}
+
+func (cmd *DsWatchDir) Execute([]string) error {
+	cmd.SetupLogging()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	return cmd.run(ctx, cmd.Client().DownloadStation())
+}
+
+// run watches directory till context canceled.
+func (cmd *DsWatchDir) run(ctx context.Context, ds *client.DownloadStation) error {
+	root, err := filepath.Abs(cmd.Args.Dir)
+	if err != nil {
+		return fmt.Errorf("resolve directory: %w", err)
+	}
+	cmd.root = root
+	cmd.Done = cmd.resolve(cmd.Done)
+	cmd.Failed = cmd.resolve(cmd.Failed)
+	cmd.ds = ds
+	cmd.pending = make(map[string]*time.Timer)
+	cmd.attempts = make(map[string]int)
+	cmd.ready = make(chan string)
+
+	cmd.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer cmd.watcher.Close()
+
+	if err := cmd.addDir(ctx, root); err != nil {
+		return err
+	}
+	slog.Info("watching directory", "dir", root, "done", cmd.Done, "failed", cmd.Failed)
+
+	for {
+		select {
+		case <-ctx.Done():
+			cmd.stopTimers()
+			return nil
+		case event, ok := <-cmd.watcher.Events:
+			if !ok {
+				return nil
+			}
+			cmd.handle(ctx, event)
+		case err, ok := <-cmd.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Error("watcher error", "error", err)
+		case file := <-cmd.ready:
+			cmd.process(ctx, file)
+		}
+	}
+}
+
+func (cmd *DsWatchDir) resolve(dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(cmd.root, dir)
+}
+
+// addDir watches directory and all its subdirectories (except done and failed) and schedules existing files.
+func (cmd *DsWatchDir) addDir(ctx context.Context, dir string) error {
+	return filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if cmd.skipped(file) {
+				return filepath.SkipDir
+			}
+			if err := cmd.watcher.Add(file); err != nil {
+				return fmt.Errorf("watch %s: %w", file, err)
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			cmd.schedule(ctx, file, cmd.Settle)
+		}
+		return nil
+	})
+}
+
+func (cmd *DsWatchDir) skipped(dir string) bool {
+	return dir == cmd.Done || dir == cmd.Failed
+}
+
+func (cmd *DsWatchDir) handle(ctx context.Context, event fsnotify.Event) {
+	switch {
+	case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+		stat, err := os.Stat(event.Name)
+		if err != nil {
+			return
+		}
+		if stat.IsDir() {
+			if err := cmd.addDir(ctx, event.Name); err != nil {
+				slog.Error("failed watch directory", "dir", event.Name, "error", err)
+			}
+			return
+		}
+		cmd.schedule(ctx, event.Name, cmd.Settle)
+	case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+		cmd.cancel(event.Name)
+	}
+}
+
+// schedule file processing after delay. Repeated scheduling postpones processing, so file is processed only when
+// it's not changed during the delay. File is dropped if context canceled before it was taken for processing.
+func (cmd *DsWatchDir) schedule(ctx context.Context, file string, delay time.Duration) {
+	name := filepath.Base(file)
+	if !isTaskFile(name) || strings.HasPrefix(name, ".") {
+		return
+	}
+	cmd.lock.Lock()
+	defer cmd.lock.Unlock()
+	if timer, ok := cmd.pending[file]; ok {
+		timer.Reset(delay)
+		return
+	}
+	cmd.pending[file] = time.AfterFunc(delay, func() {
+		cmd.lock.Lock()
+		delete(cmd.pending, file)
+		cmd.lock.Unlock()
+		select {
+		case cmd.ready <- file:
+		case <-ctx.Done():
+		}
+	})
+}
+
+func (cmd *DsWatchDir) cancel(file string) {
+	cmd.lock.Lock()
+	defer cmd.lock.Unlock()
+	if timer, ok := cmd.pending[file]; ok {
+		timer.Stop()
+		delete(cmd.pending, file)
+	}
+}
+
+func (cmd *DsWatchDir) stopTimers() {
+	cmd.lock.Lock()
+	defer cmd.lock.Unlock()
+	for file, timer := range cmd.pending {
+		timer.Stop()
+		delete(cmd.pending, file)
+	}
+}
+
+// process uploads file and moves it to done or failed directory. Connection errors are retried.
+func (cmd *DsWatchDir) process(ctx context.Context, file string) {
+	if _, err := os.Stat(file); err != nil {
+		return // already moved or removed
+	}
+	rel, err := filepath.Rel(cmd.root, filepath.Dir(file))
+	if err != nil {
+		slog.Error("failed resolve relative path", "file", file, "error", err)
+		return
+	}
+	destination := cmd.destination(rel)
+	logger := slog.With("file", file, "destination", destination)
+
+	ids, err := cmd.upload(ctx, file, destination)
+	if err != nil && ctx.Err() != nil {
+		return // interrupted, file will be processed on next start
+	}
+	if err != nil && isRetryable(err) && cmd.attempts[file] < cmd.Retries {
+		cmd.attempts[file]++
+		logger.Warn("failed upload file, will retry", "attempt", cmd.attempts[file], "error", err)
+		cmd.schedule(ctx, file, cmd.RetryInterval)
+		return
+	}
+	delete(cmd.attempts, file)
+
+	target := filepath.Join(cmd.Done, rel)
+	if err != nil {
+		logger.Error("failed upload file", "error", err)
+		target = filepath.Join(cmd.Failed, rel)
+	} else {
+		logger.Info("created task in Download Station", "ids", ids)
+	}
+	moved, err := moveFile(file, target)
+	if err != nil {
+		logger.Error("failed move file", "target", target, "error", err)
+		return
+	}
+	logger.Debug("file moved", "target", moved)
+}
+
+// destination maps relative subdirectory to Download Station destination. Root directory uses default destination.
+func (cmd *DsWatchDir) destination(rel string) string {
+	if rel == "." {
+		return cmd.Destination
+	}
+	return path.Clean(filepath.ToSlash(rel))
+}
+
+func (cmd *DsWatchDir) upload(ctx context.Context, file string, destination string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	params := client.DownloadTask{
+		FileType:    taskFileType(file),
+		Destination: destination,
+	}
+	if err := inspectFile(&params, data, nil); err != nil {
+		return nil, err
+	}
+	params.File = bytes.NewReader(data)
+	return cmd.ds.Create(ctx, params)
+}
+
+// isRetryable checks that error is caused by connection problems or Synology server failure (5xx status).
+// Other errors (ex: invalid file or rejected by Synology) will not be fixed by retry.
+func isRetryable(err error) bool {
+	var netErr net.Error
+	var urlErr *url.Error
+	var statusErr *client.StatusError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.As(err, &netErr), errors.As(err, &urlErr):
+		return true
+	case errors.As(err, &statusErr):
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+	return false
+}
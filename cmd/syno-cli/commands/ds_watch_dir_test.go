@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestDsWatchDir_destination(t *testing.T) {
+	cmd := &DsWatchDir{Destination: "Downloads"}
+	require.Equal(t, "Downloads", cmd.destination("."))
+	require.Equal(t, "video", cmd.destination("video"))
+	require.Equal(t, "video/movies", cmd.destination(filepath.Join("video", "movies")))
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"connection refused": {err: fmt.Errorf("call API: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), retryable: true},
+		"url error":          {err: fmt.Errorf("call API: %w", &url.Error{Op: "Post", URL: "http://nas", Err: io.ErrUnexpectedEOF}), retryable: true},
+		"server error":       {err: fmt.Errorf("call API: %w", &client.StatusError{Code: http.StatusServiceUnavailable}), retryable: true},
+		"client error":       {err: fmt.Errorf("call API: %w", &client.StatusError{Code: http.StatusNotFound})},
+		"canceled request":   {err: &url.Error{Op: "Post", URL: "http://nas", Err: context.Canceled}},
+		"rejected":           {err: fmt.Errorf("application API error: %w", &client.RemoteError{Code: 403})},
+		"invalid torrent":    {err: fmt.Errorf("parse: %w", client.ErrInvalidTorrent)},
+		"unknown file type":  {err: fmt.Errorf("detect file type: %w", client.ErrUnknownFileType)},
+		"plain error":        {err: errors.New("files selection supported only for torrent files")},
+		"file removed":       {err: fmt.Errorf("read file: %w", fs.ErrNotExist)},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.retryable, isRetryable(tc.err))
+		})
+	}
+}
+
+func TestDsWatchDir_schedule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := &DsWatchDir{
+		pending: make(map[string]*time.Timer),
+		ready:   make(chan string, 10),
+	}
+	const delay = 100 * time.Millisecond
+	started := time.Now()
+	cmd.schedule(ctx, "/tmp/a.torrent", delay)
+	cmd.schedule(ctx, "/tmp/notes.md", delay)
+	cmd.schedule(ctx, "/tmp/.hidden.torrent", delay)
+	time.Sleep(delay / 2)
+	cmd.schedule(ctx, "/tmp/a.torrent", delay) // file changed: postpone
+
+	select {
+	case file := <-cmd.ready:
+		require.Equal(t, "/tmp/a.torrent", file)
+		require.GreaterOrEqual(t, time.Since(started), delay+delay/2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("file not scheduled")
+	}
+	select {
+	case file := <-cmd.ready:
+		t.Fatalf("unexpected file %s", file)
+	case <-time.After(2 * delay):
+	}
+	require.Empty(t, cmd.pending)
+
+	cmd.schedule(ctx, "/tmp/b.torrent", delay)
+	cmd.cancel("/tmp/b.torrent")
+	select {
+	case file := <-cmd.ready:
+		t.Fatalf("canceled file %s processed", file)
+	case <-time.After(2 * delay):
+	}
+}
+
+func TestDsWatchDir_schedule_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := &DsWatchDir{
+		pending: make(map[string]*time.Timer),
+		ready:   make(chan string), // nobody reads after run returned
+	}
+	const delay = 10 * time.Millisecond
+	cmd.schedule(ctx, "/tmp/a.torrent", delay)
+	cancel()
+	time.Sleep(5 * delay) // timer fired after cancellation
+
+	select {
+	case file := <-cmd.ready:
+		t.Fatalf("timer of canceled watcher still waits to deliver %s", file)
+	case <-time.After(delay):
+	}
+}
+
+func TestDsWatchDir_run(t *testing.T) {
+	dir := t.TempDir()
+	writeTorrent := func(rel, name string) {
+		file := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0700))
+		content := strings.Replace(testTorrent, "8:disk.iso", fmt.Sprintf("%d:%s", len(name), name), 1)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	}
+
+	var lock sync.Mutex
+	var destinations = make(map[string]string) // torrent name -> destination
+	var failedOnce bool
+	syno := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		if request.FormValue("api") == "SYNO.DownloadStation.Task" && request.FormValue("method") == "list" {
+			return client.DownloadTasks{} // snapshot before the first creation
+		}
+		file, _, err := request.FormFile("torrent")
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		info, err := io.ReadAll(file)
+		assert.NoError(t, err)
+		torrent, err := client.ParseTorrent(info)
+		assert.NoError(t, err)
+
+		lock.Lock()
+		defer lock.Unlock()
+		if torrent.Name == "retry.iso" && !failedOnce {
+			failedOnce = true
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return nil
+		}
+		destinations[torrent.Name] = strings.Trim(request.FormValue("destination"), `"`)
+		return map[string]any{"task_id": []string{"dbid_" + torrent.Name}}
+	})
+
+	writeTorrent("a.torrent", "a.iso") // existed before start
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("not a task"), 0600))
+
+	cmd := &DsWatchDir{
+		Destination:   "Downloads",
+		Done:          "done",
+		Failed:        "failed",
+		Settle:        50 * time.Millisecond,
+		Retries:       3,
+		RetryInterval: 50 * time.Millisecond,
+	}
+	cmd.Args.Dir = dir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, cmd.run(ctx, syno.Client().DownloadStation()))
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	require.Eventually(t, func() bool {
+		return exists(filepath.Join(dir, "done", "a.torrent"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	writeTorrent(filepath.Join("video", "movies", "b.torrent"), "b.iso")
+	writeTorrent("retry.torrent", "retry.iso")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "video", "bad.torrent"), []byte("not a torrent"), 0600))
+
+	require.Eventually(t, func() bool {
+		return exists(filepath.Join(dir, "done", "video", "movies", "b.torrent")) &&
+			exists(filepath.Join(dir, "done", "retry.torrent")) &&
+			exists(filepath.Join(dir, "failed", "video", "bad.torrent"))
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	require.True(t, exists(filepath.Join(dir, "notes.md")), "not task files should be kept")
+	require.False(t, exists(filepath.Join(dir, "video", "bad.torrent")))
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, map[string]string{
+		"a.iso":     "Downloads",
+		"b.iso":     "video/movies",
+		"retry.iso": "Downloads",
+	}, destinations)
+	require.True(t, failedOnce)
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
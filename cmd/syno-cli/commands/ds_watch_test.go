@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestDiffTasks(t *testing.T) {
+	task := func(id string, status client.TaskStatus) client.ScheduledTask {
+		return client.ScheduledTask{ID: id, Title: id + ".iso", Status: status, Size: 100}
+	}
+	state := func(tasks ...client.ScheduledTask) map[string]client.ScheduledTask {
+		var ans = make(map[string]client.ScheduledTask, len(tasks))
+		for _, t := range tasks {
+			ans[t.ID] = t
+		}
+		return ans
+	}
+	type event struct {
+		Event      string
+		ID         string
+		PrevStatus client.TaskStatus
+	}
+
+	cases := []struct {
+		name    string
+		prev    map[string]client.ScheduledTask
+		current []client.ScheduledTask
+		events  []event
+	}{
+		{
+			name:    "first poll reports existing tasks as added only",
+			current: []client.ScheduledTask{task("a", client.TaskStatusDownloading), task("b", client.TaskStatusFinished), task("c", client.TaskStatusError)},
+			events:  []event{{Event: taskAdded, ID: "a"}, {Event: taskAdded, ID: "b"}, {Event: taskAdded, ID: "c"}},
+		},
+		{
+			name: "first poll without tasks",
+		},
+		{
+			name:    "no changes",
+			prev:    state(task("a", client.TaskStatusDownloading)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusDownloading)},
+		},
+		{
+			name: "progress change is not reported",
+			prev: state(task("a", client.TaskStatusDownloading)),
+			current: []client.ScheduledTask{{ID: "a", Status: client.TaskStatusDownloading, Size: 100,
+				Additional: client.TaskAdditional{Transfer: client.TaskTransfer{SizeDownloaded: 50}}}},
+		},
+		{
+			name:    "new task",
+			prev:    state(),
+			current: []client.ScheduledTask{task("a", client.TaskStatusWaiting)},
+			events:  []event{{Event: taskAdded, ID: "a"}},
+		},
+		{
+			name:    "new completed task",
+			prev:    state(),
+			current: []client.ScheduledTask{task("a", client.TaskStatusSeeding)},
+			events:  []event{{Event: taskAdded, ID: "a"}, {Event: taskCompleted, ID: "a"}},
+		},
+		{
+			name:    "new errored task",
+			prev:    state(),
+			current: []client.ScheduledTask{task("a", client.TaskStatusError)},
+			events:  []event{{Event: taskAdded, ID: "a"}, {Event: taskErrored, ID: "a"}},
+		},
+		{
+			name:    "status changed",
+			prev:    state(task("a", client.TaskStatusWaiting)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusDownloading)},
+			events:  []event{{Event: taskStatusChanged, ID: "a", PrevStatus: client.TaskStatusWaiting}},
+		},
+		{
+			name:    "completed on transition",
+			prev:    state(task("a", client.TaskStatusDownloading)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusFinished)},
+			events: []event{
+				{Event: taskStatusChanged, ID: "a", PrevStatus: client.TaskStatusDownloading},
+				{Event: taskCompleted, ID: "a"},
+			},
+		},
+		{
+			name:    "completed is not repeated between completed statuses",
+			prev:    state(task("a", client.TaskStatusSeeding)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusFinished)},
+			events:  []event{{Event: taskStatusChanged, ID: "a", PrevStatus: client.TaskStatusSeeding}},
+		},
+		{
+			name:    "errored",
+			prev:    state(task("a", client.TaskStatusDownloading)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusError)},
+			events: []event{
+				{Event: taskStatusChanged, ID: "a", PrevStatus: client.TaskStatusDownloading},
+				{Event: taskErrored, ID: "a"},
+			},
+		},
+		{
+			name:    "removed",
+			prev:    state(task("a", client.TaskStatusDownloading), task("b", client.TaskStatusFinished)),
+			current: []client.ScheduledTask{task("a", client.TaskStatusDownloading)},
+			events:  []event{{Event: taskRemoved, ID: "b"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []event
+			for _, e := range diffTasks(tc.prev, tc.current) {
+				events = append(events, event{Event: e.Event, ID: e.ID, PrevStatus: e.PrevStatus})
+			}
+			require.Equal(t, tc.events, events)
+		})
+	}
+}
+
+func TestDiffTasks_errorDetail(t *testing.T) {
+	current := []client.ScheduledTask{{
+		ID:          "a",
+		Title:       "a.iso",
+		Status:      client.TaskStatusError,
+		Size:        200,
+		StatusExtra: &client.TaskStatusExtra{ErrorDetail: "broken_link"},
+		Additional:  client.TaskAdditional{Transfer: client.TaskTransfer{SizeDownloaded: 50}},
+	}}
+	events := diffTasks(map[string]client.ScheduledTask{}, current)
+	require.Len(t, events, 2)
+	require.Equal(t, taskErrored, events[1].Event)
+	require.Equal(t, "broken_link", events[1].Error)
+	require.Equal(t, "a.iso", events[1].Title)
+	require.InDelta(t, 0.25, events[1].Progress, 1e-9)
+}
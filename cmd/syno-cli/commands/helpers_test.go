@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+// fakeSynology serves APIs info and authorization; other calls are answered by handler,
+// which returns content of data field of successful response, apiErrorCode for failed response,
+// rawContent for non-JSON response or httpStatus.
+func fakeSynology(t *testing.T, handler func(writer http.ResponseWriter, request *http.Request) any) SynoClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var data any
+		switch {
+		case request.URL.Path == "/webapi/query.cgi":
+			data = map[string]client.API{
+				"SYNO.API.Auth":                   {MaxVersion: 6, Path: "entry.cgi"},
+				"SYNO.DownloadStation.Task":       {MaxVersion: 1, Path: "DownloadStation/task.cgi"},
+				"SYNO.DownloadStation2.Task":      {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.DownloadStation2.Task.List": {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.FileStation.List":           {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.FileStation.Upload":         {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.FileStation.Download":       {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.FileStation.Delete":         {MaxVersion: 2, Path: "entry.cgi"},
+				"SYNO.Core.Certificate":           {MaxVersion: 1, Path: "entry.cgi"},
+				"SYNO.Core.Certificate.CRT":       {MaxVersion: 1, Path: "entry.cgi"},
+				"SYNO.Core.Certificate.Service":   {MaxVersion: 1, Path: "entry.cgi"},
+				"SYNO.Core.DSMNotify":             {MaxVersion: 1, Path: "entry.cgi"},
+			}
+		case request.FormValue("api") == "SYNO.API.Auth":
+			data = map[string]string{"sid": "test"}
+		default:
+			data = handler(writer, request)
+		}
+		switch data := data.(type) {
+		case httpStatus:
+			writer.WriteHeader(int(data))
+		case apiErrorCode:
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": false, "error": map[string]any{"code": data}})
+		case rawContent:
+			writer.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = writer.Write(data)
+		default:
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": true, "data": data})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return SynoClient{User: "admin", Password: "admin", URL: srv.URL, Timeout: time.Minute}
+}
+
+// apiErrorCode is returned by fakeSynology handler to respond with API error.
+type apiErrorCode int
+
+// rawContent is returned by fakeSynology handler to respond with file content.
+type rawContent []byte
+
+// httpStatus is returned by fakeSynology handler to respond with HTTP status without API response,
+// ex: for unexpected calls, which are recorded by handler and checked by test.
+type httpStatus int
+
+// certsList is response of SYNO.Core.Certificate.CRT list with certificates, formatted as Synology does.
+func certsList(t *testing.T, list ...client.Certificate) any {
+	t.Helper()
+	var certs = make([]map[string]any, 0, len(list))
+	for _, crt := range list {
+		data, err := json.Marshal(crt)
+		require.NoError(t, err)
+		var item map[string]any
+		require.NoError(t, json.Unmarshal(data, &item))
+		item["valid_from"] = crt.ValidFrom.Time().UTC().Format(synoTimeFormat)
+		item["valid_till"] = crt.ValidTill.Time().UTC().Format(synoTimeFormat)
+		certs = append(certs, item)
+	}
+	return map[string]any{"certificates": certs}
+}
+
+const synoTimeFormat = "Jan _2 15:04:05 2006 MST"
+
+// testCertPEM generates self-signed certificate for domains valid till notAfter. Returns PEM encoded certificate and key.
+func testCertPEM(t *testing.T, notAfter time.Time, domains ...string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	rawKey, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawKey})
+}
+
+// fakeFileStation is in-memory File Station for fakeSynology handler. Files are stored by absolute path.
+type fakeFileStation struct {
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+func (ffs *fakeFileStation) handle(t *testing.T, request *http.Request) any {
+	ffs.lock.Lock()
+	defer ffs.lock.Unlock()
+	if ffs.files == nil {
+		ffs.files = make(map[string][]byte)
+	}
+	var paths []string
+	if request.FormValue("api") == "SYNO.FileStation.Download" || request.FormValue("api") == "SYNO.FileStation.Delete" {
+		if !assert.NoError(t, json.Unmarshal([]byte(request.FormValue("path")), &paths)) || !assert.Len(t, paths, 1) {
+			return apiErrorCode(fileStationErrBadRequest)
+		}
+	}
+	switch request.FormValue("api") {
+	case "SYNO.FileStation.Upload":
+		file, header, err := request.FormFile("file")
+		if !assert.NoError(t, err) {
+			return apiErrorCode(fileStationErrBadRequest)
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if !assert.NoError(t, err) {
+			return apiErrorCode(fileStationErrBadRequest)
+		}
+		ffs.files[path.Join(request.FormValue("path"), header.Filename)] = data
+		return nil
+	case "SYNO.FileStation.Download":
+		data, ok := ffs.files[paths[0]]
+		if !ok {
+			return apiErrorCode(fileStationErrNoSuchFile)
+		}
+		return rawContent(data)
+	case "SYNO.FileStation.List":
+		folder := request.FormValue("folder_path")
+		var files []client.FileInfo
+		var dirs = make(map[string]bool)
+		for name := range ffs.files {
+			rel, ok := strings.CutPrefix(name, folder+"/")
+			if !ok {
+				continue
+			}
+			child, _, nested := strings.Cut(rel, "/")
+			if !nested {
+				files = append(files, client.FileInfo{Name: child, Path: name})
+			} else if !dirs[child] {
+				dirs[child] = true
+				files = append(files, client.FileInfo{Name: child, Path: folder + "/" + child, IsDir: true})
+			}
+		}
+		if len(files) == 0 {
+			return apiErrorCode(fileStationErrNoSuchFile)
+		}
+		slices.SortFunc(files, func(a, b client.FileInfo) int { return strings.Compare(a.Path, b.Path) })
+		return map[string]any{"files": files}
+	case "SYNO.FileStation.Delete":
+		var found bool
+		for name := range ffs.files {
+			if name == paths[0] || strings.HasPrefix(name, paths[0]+"/") {
+				delete(ffs.files, name)
+				found = true
+			}
+		}
+		if !found {
+			return apiErrorCode(fileStationErrNoSuchFile)
+		}
+		return nil
+	default:
+		assert.Fail(t, "unexpected API", request.FormValue("api"))
+		return apiErrorCode(fileStationErrBadRequest)
+	}
+}
+
+const (
+	fileStationErrBadRequest = 101
+	fileStationErrNoSuchFile = 408
+)
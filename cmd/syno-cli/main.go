@@ -20,14 +20,35 @@ var (
 //nolint:staticcheck
 type Config struct {
 	Cert struct {
-		List   commands.CertsList   `command:"list" description:"list certificates" alias:"ls" alias:"l"`
-		Upload commands.CertsUpload `command:"upload" description:"upload certificate" alias:"up" alias:"u"`
-		Delete commands.CertsDelete `command:"delete" description:"delete certificate" alias:"remove" alias:"rm"  alias:"del" alias:"d"`
-		Auto   commands.CertsAuto   `command:"auto" description:"automatically issue and push certificates" alias:"dns01" alias:"lego" alias:"a"`
+		List    commands.CertsList    `command:"list" description:"list certificates" alias:"ls" alias:"l"`
+		Upload  commands.CertsUpload  `command:"upload" description:"upload certificate" alias:"up" alias:"u"`
+		Delete  commands.CertsDelete  `command:"delete" description:"delete certificate" alias:"remove" alias:"rm"  alias:"del" alias:"d"`
+		Auto    commands.CertsAuto    `command:"auto" description:"automatically issue and push certificates" alias:"dns01" alias:"lego" alias:"a"`
+		Apply   commands.CertsApply   `command:"apply" description:"converge certificates and services bindings to state file"`
+		Revoke  commands.CertsRevoke  `command:"revoke" description:"revoke ACME certificate and delete it from Synology"`
+		Prune   commands.CertsPrune   `command:"prune" description:"delete expired or broken certificates not used by any service"`
+		Migrate commands.CertsMigrate `command:"migrate-cache" description:"copy cert auto cache between storages" alias:"migrate"`
 	} `command:"cert" description:"manager certificates" alias:"certificates" alias:"certificate" alias:"certs" alias:"cert" alias:"c"`
 	DS struct {
-		Create commands.DsCreate `command:"create" description:"create task" alias:"add" alias:"new" alias:"c"`
-		List   commands.DsList   `command:"list" description:"list tasks" alias:"ls" alias:"l"`
+		Create   commands.DsCreate   `command:"create" description:"create task" alias:"add" alias:"new" alias:"c"`
+		List     commands.DsList     `command:"list" description:"list tasks" alias:"ls" alias:"l"`
+		Show     commands.DsShow     `command:"show" description:"show task details, files, trackers and peers" alias:"info"`
+		Watch    commands.DsWatch    `command:"watch" description:"watch tasks progress or stream change events"`
+		Wait     commands.DsWait     `command:"wait" description:"wait till tasks are finished or seeding"`
+		WatchDir commands.DsWatchDir `command:"watch-dir" description:"upload torrent, nzb and txt files appeared in directory"`
+		Pause    commands.DsPause    `command:"pause" description:"pause tasks"`
+		Resume   commands.DsResume   `command:"resume" description:"resume tasks"`
+		Remove   commands.DsRemove   `command:"remove" description:"delete tasks" alias:"rm" alias:"delete" alias:"del"`
+		Move     commands.DsMove     `command:"move" description:"change tasks destination" alias:"mv"`
+		Config   struct {
+			Get commands.DsConfigGet `command:"get" description:"show Download Station info and configuration"`
+			Set commands.DsConfigSet `command:"set" description:"change Download Station configuration"`
+		} `command:"config" description:"show or change configuration" alias:"cfg"`
+		Schedule struct {
+			Get commands.DsScheduleGet `command:"get" description:"show schedule state"`
+			Set commands.DsScheduleSet `command:"set" description:"enable or disable schedule"`
+		} `command:"schedule" description:"show or change schedule state"`
+		Limit commands.DsLimit `command:"limit" description:"set or show speed limits" alias:"throttle"`
 	} `command:"ds" description:"download station" alias:"download-station" alias:"download" alias:"dl" alias:"d"`
 }
 
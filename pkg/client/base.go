@@ -94,14 +94,16 @@ func New(cfg Config) *Client {
 }
 
 type Client struct {
-	client      HTTPClient
-	user        string
-	password    string
-	baseURL     string
-	authorized  atomic.Bool
-	authLock    sync.Mutex
-	versionLock sync.Mutex
-	versions    map[string]API
+	client         HTTPClient
+	user           string
+	password       string
+	baseURL        string
+	authorized     atomic.Bool
+	authLock       sync.Mutex
+	versionLock    sync.Mutex
+	versions       map[string]API
+	returnsTaskIDs atomic.Bool // Download Station returned IDs of created tasks
+	createLock     sync.Mutex  // serializes tasks creation when IDs detected by difference of tasks lists
 }
 
 // WithClient returns copy of Synology client with custom HTTP client.
@@ -227,7 +229,7 @@ func (cl *Client) doPost(ctx context.Context, path string, queryParams map[strin
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("status %d: %w", res.StatusCode, ErrBadStatus)
+		return &StatusError{Code: res.StatusCode}
 	}
 
 	var rawResponse apiResponse
@@ -249,6 +251,42 @@ func (cl *Client) doPost(ctx context.Context, path string, queryParams map[strin
 }
 
 func (cl *Client) directCall(ctx context.Context, apiName string, method string, params []field) (*http.Response, error) {
+	res, err := cl.doCall(ctx, apiName, method, params)
+	if err != nil {
+		return nil, err
+	}
+	return checkAPIError(res)
+}
+
+// rawCall is the same as directCall, but response could be non-JSON (ex: file download).
+// API error is checked only for JSON responses.
+func (cl *Client) rawCall(ctx context.Context, apiName string, method string, params []field) (*http.Response, error) {
+	res, err := cl.doCall(ctx, apiName, method, params)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		return res, nil
+	}
+	return checkAPIError(res)
+}
+
+// checkAPIError tries to parse body as API response and returns error if it's not successful.
+// Body is preserved for future reading.
+func checkAPIError(res *http.Response) (*http.Response, error) {
+	var buffer bytes.Buffer
+	if err := asAPIError(io.TeeReader(res.Body, &buffer)); err != nil {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("application API error: %w", err)
+	}
+	res.Body = &readCloser{
+		Reader: io.MultiReader(&buffer, res.Body),
+		Closer: res.Body,
+	}
+	return res, nil
+}
+
+func (cl *Client) doCall(ctx context.Context, apiName string, method string, params []field) (*http.Response, error) {
 	info, err := cl.APIVersion(ctx, apiName)
 	if err != nil {
 		return nil, fmt.Errorf("get API %s version: %w", apiName, err)
@@ -281,25 +319,12 @@ func (cl *Client) directCall(ctx context.Context, apiName string, method string,
 	if err != nil {
 		return nil, fmt.Errorf("call API: %w", err)
 	}
-	defer res.Body.Close()
 
 	//nolint:mnd
 	if res.StatusCode/100 != 2 {
 		_ = res.Body.Close()
-		return nil, fmt.Errorf("status %d: %w", res.StatusCode, ErrBadStatus)
-	}
-
-	// try to parse body as API response
-	var buffer bytes.Buffer
-	if err := asAPIError(io.TeeReader(res.Body, &buffer)); err != nil {
-		_ = res.Body.Close()
-		return nil, fmt.Errorf("application API error: %w", err)
+		return nil, &StatusError{Code: res.StatusCode}
 	}
-	res.Body = &readCloser{
-		Reader: io.MultiReader(&buffer, res.Body),
-		Closer: res.Body,
-	}
-
 	return res, nil
 }
 
@@ -454,6 +479,19 @@ func (e *RemoteError) Error() string {
 	return "API error code: " + strconv.FormatInt(e.Code, 10)
 }
 
+// StatusError is non-successful HTTP status of response. Matches ErrBadStatus.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return "status " + strconv.Itoa(e.Code) + ": " + ErrBadStatus.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrBadStatus
+}
+
 type readCloser struct {
 	io.Reader
 	io.Closer
@@ -1,10 +1,14 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 )
 
@@ -50,6 +54,28 @@ func (ct *Certificate) Expired() bool {
 	return time.Now().After(ct.ValidTill.Time())
 }
 
+// Matches checks that certificate in Synology is the same as provided one. Synology doesn't expose serial number or
+// fingerprint, so validity period (in seconds precision), common name and alternative names are compared.
+func (ct *Certificate) Matches(crt *x509.Certificate) bool {
+	if !ct.ValidFrom.Time().Equal(crt.NotBefore.Truncate(time.Second)) || !ct.ValidTill.Time().Equal(crt.NotAfter.Truncate(time.Second)) {
+		return false
+	}
+	if ct.Subject.CommonName != crt.Subject.CommonName {
+		return false
+	}
+	var names = make(map[string]bool, len(crt.DNSNames))
+	for _, name := range crt.DNSNames {
+		names[name] = true
+	}
+	for _, name := range ct.Subject.SubAltName {
+		if !names[name] {
+			return false
+		}
+		delete(names, name)
+	}
+	return len(names) == 0
+}
+
 func (cl *Client) ListCerts(ctx context.Context) ([]Certificate, error) {
 	if err := cl.Login(ctx); err != nil {
 		return nil, fmt.Errorf("login: %w", err)
@@ -65,28 +91,50 @@ func (cl *Client) ListCerts(ctx context.Context) ([]Certificate, error) {
 	return response.Certificates, nil
 }
 
+var (
+	ErrCertExists    = errors.New("certificate with the same name already exists")
+	ErrCertNotFound  = errors.New("certificate not found")
+	ErrCertAmbiguous = errors.New("more than one certificate with the same name")
+)
+
+// UploadMode defines how UploadCert handles existent certificates.
+type UploadMode int
+
+const (
+	UploadUpsert        UploadMode = iota // replace certificate with the same name or create new one; fails if name is ambiguous
+	UploadCreateOnly                      // create new certificate; fails if name already exists
+	UploadReplaceByName                   // replace certificate with the same name; fails if name not found or ambiguous
+	UploadReplaceByID                     // replace certificate by ID; fails if ID not found
+)
+
 type NewCertificate struct {
-	Name      string    // unique logical name for certificate
-	AsDefault bool      // use certificate as default
-	Cert      io.Reader // PEM certificate
-	CA        io.Reader // optional
-	Key       io.Reader // PEM private key
+	Name           string     // unique logical name for certificate
+	AsDefault      bool       // use certificate as default
+	Cert           io.Reader  // PEM certificate
+	CA             io.Reader  // optional
+	Key            io.Reader  // PEM private key
+	SkipValidation bool       // do not validate key, certificate and CA before upload
+	Mode           UploadMode // how to handle existent certificates, default is UploadUpsert
+	ID             string     // certificate ID to replace, required for UploadReplaceByID
 }
 
-// UploadCert uploads certificate to Synology. Replaces if name (used field description) already exists.
+// UploadCert uploads certificate to Synology. By default, replaces if name (used field description) already exists,
+// see UploadMode for other options.
+// Unless SkipValidation set, content is validated locally (see ValidateCert) before any API call.
 func (cl *Client) UploadCert(ctx context.Context, draft NewCertificate) (*CertUploadResult, error) {
+	if !draft.SkipValidation {
+		if err := validateDraft(&draft); err != nil {
+			return nil, fmt.Errorf("validate certificate: %w", err)
+		}
+	}
 	var info CertUploadResult
 	list, err := cl.ListCerts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list certificates: %w", err)
 	}
-	// find if already exists
-	var id string
-	for _, crt := range list {
-		if crt.Description == draft.Name {
-			id = crt.ID
-			break
-		}
+	id, err := uploadTarget(list, draft)
+	if err != nil {
+		return nil, err
 	}
 	params := map[string]interface{}{
 		"key": fileAttachment{
@@ -114,6 +162,62 @@ func (cl *Client) UploadCert(ctx context.Context, draft NewCertificate) (*CertUp
 	return &info, cl.callAPI(ctx, "SYNO.Core.Certificate", "import", params, &info)
 }
 
+// uploadTarget finds ID of certificate which should be replaced by draft according to upload mode.
+// Empty ID means new certificate.
+func uploadTarget(list []Certificate, draft NewCertificate) (string, error) {
+	if draft.Mode == UploadReplaceByID {
+		for _, crt := range list {
+			if crt.ID == draft.ID {
+				return crt.ID, nil
+			}
+		}
+		return "", fmt.Errorf("%w: id %q", ErrCertNotFound, draft.ID)
+	}
+
+	found := FindCertsByName(list, draft.Name)
+	switch {
+	case len(found) > 1:
+		return "", fmt.Errorf("%w: %q", ErrCertAmbiguous, draft.Name)
+	case len(found) == 1 && draft.Mode == UploadCreateOnly:
+		return "", fmt.Errorf("%w: %q (id %s)", ErrCertExists, draft.Name, found[0].ID)
+	case len(found) == 0 && draft.Mode == UploadReplaceByName:
+		return "", fmt.Errorf("%w: name %q", ErrCertNotFound, draft.Name)
+	case len(found) == 1:
+		return found[0].ID, nil
+	}
+	return "", nil
+}
+
+// FindCertsByName returns all certificates with matched name (description).
+func FindCertsByName(list []Certificate, name string) []Certificate {
+	var ans []Certificate
+	for _, crt := range list {
+		if crt.Description == name {
+			ans = append(ans, crt)
+		}
+	}
+	return ans
+}
+
+// FindCert finds certificate by ID or, if no ID matched, by unique name.
+// Returns ErrCertNotFound if nothing found and ErrCertAmbiguous if name is not unique.
+func FindCert(list []Certificate, idOrName string) (*Certificate, error) {
+	for _, crt := range list {
+		if crt.ID == idOrName {
+			return &crt, nil
+		}
+	}
+	found := FindCertsByName(list, idOrName)
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrCertNotFound, idOrName)
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrCertAmbiguous, idOrName)
+	}
+}
+
 // DeleteCertByID deletes certificate by known ID (not name).
 func (cl *Client) DeleteCertByID(ctx context.Context, id string) (*ServerStatus, error) {
 	var info ServerStatus
@@ -129,6 +233,79 @@ func (cl *Client) DeleteCertByID(ctx context.Context, id string) (*ServerStatus,
 	}, &info)
 }
 
+// ServiceBinding changes certificate used by service.
+type ServiceBinding struct {
+	Service Service `json:"service"`
+	OldID   string  `json:"old_id"` // current certificate ID
+	ID      string  `json:"id"`     // new certificate ID
+}
+
+// BindServices switches services to another certificates. Web server may be restarted.
+func (cl *Client) BindServices(ctx context.Context, bindings []ServiceBinding) (*ServerStatus, error) {
+	var info ServerStatus
+	if err := cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	settings, err := json.Marshal(bindings)
+	if err != nil {
+		return nil, fmt.Errorf("marshal settings: %w", err)
+	}
+	return &info, cl.callAPI(ctx, "SYNO.Core.Certificate.Service", "set", map[string]interface{}{
+		"settings": string(settings),
+	}, &info)
+}
+
+// BoundService is service with ID of certificate it currently uses.
+type BoundService struct {
+	Service
+	CertificateID string
+}
+
+// Ref is unique human-readable service reference: subscriber/service.
+func (bs *BoundService) Ref() string {
+	return bs.Subscriber + "/" + bs.Service.Service
+}
+
+// Matches checks if service referenced by display name (case-insensitive), service name or subscriber/service.
+func (bs *BoundService) Matches(ref string) bool {
+	return strings.EqualFold(bs.DisplayName, ref) || bs.Service.Service == ref || bs.Ref() == ref
+}
+
+// ListServices collects services from certificates. Each service is bound to exactly one certificate, so
+// certificates list contains all services which can be bound.
+func ListServices(list []Certificate) []BoundService {
+	var ans []BoundService
+	for _, crt := range list {
+		for _, svc := range crt.Services {
+			ans = append(ans, BoundService{Service: svc, CertificateID: crt.ID})
+		}
+	}
+	return ans
+}
+
+// validateDraft reads draft content into memory, validates it and replaces readers by buffered copies.
+func validateDraft(draft *NewCertificate) error {
+	key, err := io.ReadAll(draft.Key)
+	if err != nil {
+		return fmt.Errorf("read key: %w", err)
+	}
+	cert, err := io.ReadAll(draft.Cert)
+	if err != nil {
+		return fmt.Errorf("read certificate: %w", err)
+	}
+	var ca []byte
+	if draft.CA != nil {
+		ca, err = io.ReadAll(draft.CA)
+		if err != nil {
+			return fmt.Errorf("read CA: %w", err)
+		}
+		draft.CA = bytes.NewReader(ca)
+	}
+	draft.Key = bytes.NewReader(key)
+	draft.Cert = bytes.NewReader(cert)
+	return ValidateCert(key, cert, ca)
+}
+
 type CertUploadResult struct {
 	CertificateID string `json:"id"`
 	ServerStatus
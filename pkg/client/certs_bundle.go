@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var ErrNoLeaf = errors.New("leaf certificate not found")
+
+// CertBundle is certificate split to parts suitable for upload. All fields are PEM encoded.
+type CertBundle struct {
+	Key   []byte // private key, may be empty if bundle had no key
+	Cert  []byte // leaf certificate
+	Chain []byte // intermediate certificates ordered from leaf issuer to the root, may be empty
+}
+
+// NewCertificate creates upload draft from bundle.
+func (cb *CertBundle) NewCertificate(name string, asDefault bool) NewCertificate {
+	draft := NewCertificate{
+		Name:      name,
+		AsDefault: asDefault,
+		Cert:      bytes.NewReader(cb.Cert),
+		Key:       bytes.NewReader(cb.Key),
+	}
+	if len(cb.Chain) > 0 {
+		draft.CA = bytes.NewReader(cb.Chain)
+	}
+	return draft
+}
+
+// ParsePEMBundle splits combined PEM (for example: fullchain.pem with or without private key) to key, leaf and
+// chain. Certificates can be in any order: leaf is detected by private key (if presented) or as the only
+// certificate which is not issuer of others.
+func ParsePEMBundle(data []byte) (*CertBundle, error) {
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificates: %w", err)
+	}
+	var key crypto.Signer
+	if hasPrivateKey(data) {
+		key, err = ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+	}
+	return newBundle(key, certs)
+}
+
+// ParsePKCS12 decodes PKCS#12 (PFX) archive and splits it to key, leaf and chain.
+func ParsePKCS12(data []byte, password string) (*CertBundle, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("decode PKCS#12: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+	return newBundle(signer, append([]*x509.Certificate{leaf}, chain...))
+}
+
+func newBundle(key crypto.Signer, certs []*x509.Certificate) (*CertBundle, error) {
+	leaf := findLeaf(key, certs)
+	if leaf == nil {
+		return nil, ErrNoLeaf
+	}
+	var bundle CertBundle
+	if key != nil {
+		rawKey, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("marshal private key: %w", err)
+		}
+		bundle.Key = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawKey})
+	}
+	bundle.Cert = encodeCert(leaf)
+	for _, crt := range orderChain(leaf, certs) {
+		bundle.Chain = append(bundle.Chain, encodeCert(crt)...)
+	}
+	return &bundle, nil
+}
+
+// findLeaf returns certificate matched to the key. If key not defined, returns the only certificate
+// which is not signing any other certificate in the set.
+func findLeaf(key crypto.Signer, certs []*x509.Certificate) *x509.Certificate {
+	if key != nil {
+		for _, crt := range certs {
+			if publicKeyMatches(key, crt.PublicKey) {
+				return crt
+			}
+		}
+		return nil
+	}
+	var leaf *x509.Certificate
+	for _, candidate := range certs {
+		if isIssuerOfAny(candidate, certs) {
+			continue
+		}
+		if leaf != nil {
+			return nil // ambiguous
+		}
+		leaf = candidate
+	}
+	return leaf
+}
+
+// orderChain builds chain from leaf to the root using available certificates. Unrelated certificates are dropped.
+func orderChain(leaf *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	var chain []*x509.Certificate
+	var used = map[*x509.Certificate]bool{leaf: true}
+	current := leaf
+	for {
+		var parent *x509.Certificate
+		for _, candidate := range certs {
+			if !used[candidate] && current.CheckSignatureFrom(candidate) == nil {
+				parent = candidate
+				break
+			}
+		}
+		if parent == nil {
+			break
+		}
+		used[parent] = true
+		chain = append(chain, parent)
+		current = parent
+	}
+	for _, crt := range certs {
+		if !used[crt] {
+			slog.Debug("certificate not in chain, ignored", "subject", crt.Subject.String())
+		}
+	}
+	return chain
+}
+
+func isIssuerOfAny(parent *x509.Certificate, certs []*x509.Certificate) bool {
+	for _, crt := range certs {
+		if crt != parent && crt.CheckSignatureFrom(parent) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrivateKey(data []byte) bool {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return false
+		}
+		switch block.Type {
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			return true
+		}
+	}
+}
+
+func encodeCert(crt *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw})
+}
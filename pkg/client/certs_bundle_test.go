@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestParsePEMBundle(t *testing.T) {
+	root := newTestCA(t, "Root CA")
+	inter := root.intermediate(t, "Intermediate CA")
+	leaf := inter.issue(t, "example.com", time.Now().Add(24*time.Hour))
+
+	t.Run("reordered with key", func(t *testing.T) {
+		combined := bytes.Join([][]byte{root.certPEM, leaf.keyPEM, inter.certPEM, leaf.certPEM}, nil)
+		bundle, err := client.ParsePEMBundle(combined)
+		require.NoError(t, err)
+		assert.Equal(t, leaf.certPEM, bundle.Cert)
+		assert.Equal(t, append(append([]byte{}, inter.certPEM...), root.certPEM...), bundle.Chain)
+		require.NoError(t, client.ValidateCert(bundle.Key, bundle.Cert, bundle.Chain))
+	})
+
+	t.Run("fullchain without key", func(t *testing.T) {
+		combined := bytes.Join([][]byte{inter.certPEM, leaf.certPEM}, nil)
+		bundle, err := client.ParsePEMBundle(combined)
+		require.NoError(t, err)
+		assert.Empty(t, bundle.Key)
+		assert.Equal(t, leaf.certPEM, bundle.Cert)
+		assert.Equal(t, inter.certPEM, bundle.Chain)
+	})
+
+	t.Run("no leaf for key", func(t *testing.T) {
+		other := inter.issue(t, "example.org", time.Now().Add(24*time.Hour))
+		combined := bytes.Join([][]byte{other.keyPEM, inter.certPEM, leaf.certPEM}, nil)
+		_, err := client.ParsePEMBundle(combined)
+		assert.ErrorIs(t, err, client.ErrNoLeaf)
+	})
+}
+
+func TestParsePKCS12(t *testing.T) {
+	root := newTestCA(t, "Root CA")
+	inter := root.intermediate(t, "Intermediate CA")
+	leaf := inter.issue(t, "example.com", time.Now().Add(24*time.Hour))
+
+	pfx, err := pkcs12.Modern.WithRand(rand.Reader).Encode(leaf.key, leaf.cert, []*x509.Certificate{root.cert, inter.cert}, "secret")
+	require.NoError(t, err)
+
+	bundle, err := client.ParsePKCS12(pfx, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, leaf.certPEM, bundle.Cert)
+	assert.Equal(t, append(append([]byte{}, inter.certPEM...), root.certPEM...), bundle.Chain)
+	require.NoError(t, client.ValidateCert(bundle.Key, bundle.Cert, bundle.Chain))
+
+	_, err = client.ParsePKCS12(pfx, "wrong")
+	assert.Error(t, err)
+}
@@ -3,8 +3,10 @@ package client_test
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -89,3 +91,144 @@ func TestClient_DeleteCertByID(t *testing.T) {
 		assert.NotEqual(t, info.CertificateID, item.ID)
 	}
 }
+
+func TestFindCert(t *testing.T) {
+	list := []client.Certificate{
+		{ID: "a1", Description: "example.com"},
+		{ID: "b2", Description: "shared"},
+		{ID: "c3", Description: "shared"},
+	}
+
+	crt, err := client.FindCert(list, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", crt.ID)
+
+	crt, err = client.FindCert(list, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", crt.ID)
+
+	crt, err = client.FindCert(list, "c3")
+	require.NoError(t, err)
+	assert.Equal(t, "c3", crt.ID)
+
+	_, err = client.FindCert(list, "shared")
+	assert.ErrorIs(t, err, client.ErrCertAmbiguous)
+
+	_, err = client.FindCert(list, "missing")
+	assert.ErrorIs(t, err, client.ErrCertNotFound)
+}
+
+func TestCertificate_Matches(t *testing.T) {
+	ca := newTestCA(t, "Test CA")
+	leaf := ca.issue(t, "example.com", time.Now().Add(24*time.Hour))
+
+	remote := client.Certificate{
+		ValidFrom: client.CTime(leaf.cert.NotBefore),
+		ValidTill: client.CTime(leaf.cert.NotAfter),
+		Subject: client.Subject{
+			CommonName: "example.com",
+			SubAltName: []string{"example.com"},
+		},
+	}
+	assert.True(t, remote.Matches(leaf.cert))
+
+	renewed := ca.issue(t, "example.com", time.Now().Add(48*time.Hour))
+	assert.False(t, remote.Matches(renewed.cert))
+
+	remote.Subject.SubAltName = append(remote.Subject.SubAltName, "www.example.com")
+	assert.False(t, remote.Matches(leaf.cert))
+}
+
+func TestListServices(t *testing.T) {
+	list := []client.Certificate{
+		{ID: "a1", Services: []client.Service{
+			{DisplayName: "DSM Desktop Service", Service: "default", Subscriber: "system"},
+			{DisplayName: "WebDAV", Service: "webdav", Subscriber: "webdav"},
+		}},
+		{ID: "b2"},
+		{ID: "c3", Services: []client.Service{
+			{DisplayName: "Synology Drive Server", Service: "SynologyDrive", Subscriber: "SynologyDrive"},
+		}},
+	}
+
+	services := client.ListServices(list)
+	require.Len(t, services, 3)
+	assert.Equal(t, "a1", services[0].CertificateID)
+	assert.Equal(t, "system/default", services[0].Ref())
+	assert.Equal(t, "c3", services[2].CertificateID)
+
+	assert.True(t, services[0].Matches("dsm desktop service"))
+	assert.True(t, services[0].Matches("system/default"))
+	assert.True(t, services[1].Matches("webdav"))
+	assert.False(t, services[1].Matches("system/webdav"))
+}
+
+func TestClient_UploadCert_modes(t *testing.T) {
+	list := []map[string]any{
+		{"id": "a1", "desc": "example.com"},
+		{"id": "b2", "desc": "shared"},
+		{"id": "c3", "desc": "shared"},
+	}
+	cases := []struct {
+		name     string
+		certName string
+		mode     client.UploadMode
+		id       string
+		target   string // replaced certificate ID, empty means new
+		err      error
+	}{
+		{name: "upsert existent", certName: "example.com", mode: client.UploadUpsert, target: "a1"},
+		{name: "upsert new", certName: "new.example.com", mode: client.UploadUpsert},
+		{name: "upsert ambiguous", certName: "shared", mode: client.UploadUpsert, err: client.ErrCertAmbiguous},
+		{name: "create new", certName: "new.example.com", mode: client.UploadCreateOnly},
+		{name: "create existent", certName: "example.com", mode: client.UploadCreateOnly, err: client.ErrCertExists},
+		{name: "create ambiguous", certName: "shared", mode: client.UploadCreateOnly, err: client.ErrCertAmbiguous},
+		{name: "replace by name", certName: "example.com", mode: client.UploadReplaceByName, target: "a1"},
+		{name: "replace by name not found", certName: "new.example.com", mode: client.UploadReplaceByName, err: client.ErrCertNotFound},
+		{name: "replace by name ambiguous", certName: "shared", mode: client.UploadReplaceByName, err: client.ErrCertAmbiguous},
+		{name: "replace by id", certName: "shared", mode: client.UploadReplaceByID, id: "c3", target: "c3"},
+		{name: "replace by id renames", certName: "renamed", mode: client.UploadReplaceByID, id: "a1", target: "a1"},
+		{name: "replace by id not found", certName: "example.com", mode: client.UploadReplaceByID, id: "z9", err: client.ErrCertNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var imported bool
+			var target, desc string
+			var unexpected []string
+			srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+				switch request.FormValue("api") + "." + request.FormValue("method") {
+				case "SYNO.Core.Certificate.CRT.list":
+					return map[string]any{"certificates": list}
+				case "SYNO.Core.Certificate.import":
+					imported = true
+					target, desc = request.FormValue("id"), request.FormValue("desc")
+					return client.CertUploadResult{CertificateID: "new"}
+				}
+				unexpected = append(unexpected, request.FormValue("api")+" "+request.FormValue("method"))
+				return httpStatus(http.StatusInternalServerError)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			syno := client.New(client.Config{URL: srv.URL})
+			_, err := syno.UploadCert(ctx, client.NewCertificate{
+				Name:           tc.certName,
+				Cert:           strings.NewReader("cert"),
+				Key:            strings.NewReader("key"),
+				SkipValidation: true,
+				Mode:           tc.mode,
+				ID:             tc.id,
+			})
+			require.Empty(t, unexpected)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.False(t, imported, "nothing should be uploaded")
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, imported)
+			require.Equal(t, tc.target, target)
+			require.Equal(t, tc.certName, desc)
+		})
+	}
+}
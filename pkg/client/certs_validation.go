@@ -0,0 +1,133 @@
+package client
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidPEM       = errors.New("invalid PEM content")
+	ErrInvalidKey       = errors.New("invalid private key")
+	ErrInvalidCert      = errors.New("invalid certificate")
+	ErrKeyMismatch      = errors.New("private key does not match certificate")
+	ErrChainMismatch    = errors.New("certificate is not signed by intermediate")
+	ErrCertExpired      = errors.New("certificate expired")
+	ErrUnsupportedKey   = errors.New("unsupported private key type")
+	errNoPEMBlocksFound = errors.New("no PEM blocks found")
+)
+
+// ValidateCert checks locally that key, certificate and optional intermediate (CA) are consistent:
+// PEM content parses, private key matches certificate public key, intermediate signs certificate, and certificate
+// is not expired. Certificate may contain bundle; the first certificate is treated as leaf.
+// Returned errors wrap one of ErrInvalidPEM, ErrInvalidKey, ErrUnsupportedKey, ErrInvalidCert, ErrKeyMismatch,
+// ErrChainMismatch, ErrCertExpired.
+func ValidateCert(key, cert, ca []byte) error {
+	privateKey, err := ParsePrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("parse private key: %w", err)
+	}
+	certs, err := ParseCertificates(cert)
+	if err != nil {
+		return fmt.Errorf("parse certificate: %w", err)
+	}
+	leaf := certs[0]
+
+	if !publicKeyMatches(privateKey, leaf.PublicKey) {
+		return ErrKeyMismatch
+	}
+
+	if len(ca) > 0 {
+		intermediates, err := ParseCertificates(ca)
+		if err != nil {
+			return fmt.Errorf("parse intermediate certificate: %w", err)
+		}
+		if !signedByAny(leaf, intermediates) {
+			return fmt.Errorf("%w: issuer %q", ErrChainMismatch, leaf.Issuer.String())
+		}
+	}
+
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("%w: not after %s", ErrCertExpired, leaf.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ParseCertificates decodes all certificates from PEM content. At least one certificate should be presented.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		crt, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCert, err)
+		}
+		certs = append(certs, crt)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPEM, errNoPEMBlocksFound)
+	}
+	return certs, nil
+}
+
+// ParsePrivateKey decodes first private key from PEM content. Supports PKCS#1, PKCS#8 and SEC 1 (EC) encodings.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPEM, errNoPEMBlocksFound)
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+			}
+			return key, nil
+		case "EC PRIVATE KEY":
+			key, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+			}
+			return key, nil
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+			}
+			return signer, nil
+		}
+	}
+}
+
+func publicKeyMatches(key crypto.Signer, pub crypto.PublicKey) bool {
+	known, ok := key.Public().(interface{ Equal(x crypto.PublicKey) bool })
+	if !ok {
+		return false
+	}
+	return known.Equal(pub)
+}
+
+func signedByAny(leaf *x509.Certificate, parents []*x509.Certificate) bool {
+	for _, parent := range parents {
+		if leaf.CheckSignatureFrom(parent) == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestValidateCert(t *testing.T) {
+	ca := newTestCA(t, "Test CA")
+	otherCA := newTestCA(t, "Other CA")
+	leaf := ca.issue(t, "example.com", time.Now().Add(24*time.Hour))
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, client.ValidateCert(leaf.keyPEM, leaf.certPEM, ca.certPEM))
+	})
+
+	t.Run("valid without CA", func(t *testing.T) {
+		require.NoError(t, client.ValidateCert(leaf.keyPEM, leaf.certPEM, nil))
+	})
+
+	t.Run("broken PEM", func(t *testing.T) {
+		err := client.ValidateCert(leaf.keyPEM, []byte("hello world"), nil)
+		assert.ErrorIs(t, err, client.ErrInvalidPEM)
+	})
+
+	t.Run("key mismatch", func(t *testing.T) {
+		other := ca.issue(t, "example.com", time.Now().Add(24*time.Hour))
+		err := client.ValidateCert(other.keyPEM, leaf.certPEM, ca.certPEM)
+		assert.ErrorIs(t, err, client.ErrKeyMismatch)
+	})
+
+	t.Run("wrong intermediate", func(t *testing.T) {
+		err := client.ValidateCert(leaf.keyPEM, leaf.certPEM, otherCA.certPEM)
+		assert.ErrorIs(t, err, client.ErrChainMismatch)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		old := ca.issue(t, "example.com", time.Now().Add(-time.Hour))
+		err := client.ValidateCert(old.keyPEM, old.certPEM, ca.certPEM)
+		assert.ErrorIs(t, err, client.ErrCertExpired)
+	})
+}
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	return newTestCert(t, raw, key)
+}
+
+func (tc *testCert) intermediate(t *testing.T, name string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, tc.cert, key.Public(), tc.key)
+	require.NoError(t, err)
+	return newTestCert(t, raw, key)
+}
+
+func (tc *testCert) issue(t *testing.T, domain string, notAfter time.Time) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, tc.cert, key.Public(), tc.key)
+	require.NoError(t, err)
+	return newTestCert(t, raw, key)
+}
+
+func newTestCert(t *testing.T, raw []byte, key *ecdsa.PrivateKey) *testCert {
+	t.Helper()
+	crt, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+	rawKey, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return &testCert{
+		cert:    crt,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawKey}),
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"log/slog"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,9 +23,32 @@ import (
 // ENUM(unknown = "", auto, torrent, nzb, txt)
 type FileType string
 
-var ErrUnknownFileType = errors.New("unknown file type")
+// TaskStatus is status of download task.
+// ENUM(waiting, downloading, paused, finishing, finished, hash_checking, seeding, filehosting_waiting, extracting, error)
+type TaskStatus string
 
-const peekSize = 512 // should be enough for XML header, unless intentionally obfuscated
+// TaskType is protocol of download task.
+// ENUM(bt, nzb, http, https, ftp, emule)
+type TaskType string
+
+var (
+	ErrUnknownFileType = errors.New("unknown file type")
+	ErrTaskFailed      = errors.New("task failed")
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrNoFilesList     = errors.New("files list not created")
+)
+
+// peekSize should be enough for XML header (unless intentionally obfuscated) and to reach "info" dictionary of torrent
+// after long announce list.
+const peekSize = 64 * 1024
+
+const (
+	createdPollAttempts = 5           // attempts to find created tasks in tasks list in fallback mode
+	createdPollInterval = time.Second // interval between attempts
+)
+
+// DefaultPageSize is number of tasks requested per page by DownloadStation.Tasks and DownloadStation.All.
+const DefaultPageSize = 100
 
 type DownloadTask struct {
 	URL           []string  // HTTP/FTP/magnet/ED2K links or the file path starting with a shared folder.
@@ -32,6 +58,7 @@ type DownloadTask struct {
 	Password      string    // Optional. Login password for remote resource (not NAS!)
 	UnzipPassword string    // Optional. Password for unzipping download tasks
 	Destination   string    // Optional. Download destination path starting with a shared folder
+	Select        []int     // Optional. Indexes of torrent files to download (see TorrentInfo.Files). Empty means all files. Only for File.
 }
 
 type DownloadTasks struct {
@@ -41,20 +68,123 @@ type DownloadTasks struct {
 }
 
 type ScheduledTask struct {
-	ID         string `json:"id"`
-	Type       string `json:"type"`
-	Username   string `json:"username"`
-	Title      string `json:"title"`
-	Size       int64  `json:"size"`
-	Status     string `json:"status"`
-	Additional struct {
-		Detail struct {
-			CreateTime  int64  `json:"create_time"`
-			Destination string `json:"destination"`
-			Priority    string `json:"priority"`
-			URI         string `json:"uri"`
-		} `json:"detail"`
-	} `json:"additional"`
+	ID          string           `json:"id"`
+	Type        TaskType         `json:"type"`
+	Username    string           `json:"username"`
+	Title       string           `json:"title"`
+	Size        int64            `json:"size"`
+	Status      TaskStatus       `json:"status"`
+	StatusExtra *TaskStatusExtra `json:"status_extra,omitempty"` // only for error and extracting statuses
+	Additional  TaskAdditional   `json:"additional"`
+}
+
+// Completed means that task finished downloading: finished or seeding.
+func (ts TaskStatus) Completed() bool {
+	return ts == TaskStatusFinished || ts == TaskStatusSeeding
+}
+
+type TaskStatusExtra struct {
+	ErrorDetail   string `json:"error_detail,omitempty"`
+	UnzipProgress int    `json:"unzip_progress,omitempty"` // percents
+}
+
+// Progress of download in range [0, 1].
+func (st *ScheduledTask) Progress() float64 {
+	if st.Size <= 0 {
+		return 0
+	}
+	return float64(st.Additional.Transfer.SizeDownloaded) / float64(st.Size)
+}
+
+// ETA estimates remaining download time by current speed. Returns false if speed is unknown or task completed.
+func (st *ScheduledTask) ETA() (time.Duration, bool) {
+	left := st.Size - st.Additional.Transfer.SizeDownloaded
+	speed := st.Additional.Transfer.SpeedDownload
+	if left <= 0 || speed <= 0 {
+		return 0, false
+	}
+	return time.Duration(left/speed) * time.Second, true
+}
+
+// TaskFilter matches tasks by fields. Empty fields match any task.
+type TaskFilter struct {
+	Status []TaskStatus
+	Type   []TaskType
+	User   []string
+	Title  string // glob pattern, see path.Match
+}
+
+// Match checks that task satisfies all filter fields.
+func (tf *TaskFilter) Match(task *ScheduledTask) bool {
+	if len(tf.Status) > 0 && !slices.Contains(tf.Status, task.Status) {
+		return false
+	}
+	if len(tf.Type) > 0 && !slices.Contains(tf.Type, task.Type) {
+		return false
+	}
+	if len(tf.User) > 0 && !slices.Contains(tf.User, task.Username) {
+		return false
+	}
+	if tf.Title != "" {
+		if ok, _ := path.Match(tf.Title, task.Title); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// TaskAdditional is optional task information. Only requested parts are filled.
+type TaskAdditional struct {
+	Detail   TaskDetail    `json:"detail"`
+	Transfer TaskTransfer  `json:"transfer"`
+	File     []TaskFile    `json:"file,omitempty"`
+	Tracker  []TaskTracker `json:"tracker,omitempty"`
+	Peer     []TaskPeer    `json:"peer,omitempty"`
+}
+
+type TaskDetail struct {
+	CreateTime        int64  `json:"create_time"`
+	Destination       string `json:"destination"`
+	Priority          string `json:"priority"`
+	URI               string `json:"uri"`
+	ConnectedLeechers int    `json:"connected_leechers"`
+	ConnectedSeeders  int    `json:"connected_seeders"`
+	TotalPeers        int    `json:"total_peers"`
+}
+
+type TaskTransfer struct {
+	SizeDownloaded int64 `json:"size_downloaded"`
+	SizeUploaded   int64 `json:"size_uploaded"`
+	SpeedDownload  int64 `json:"speed_download"` // bytes per second
+	SpeedUpload    int64 `json:"speed_upload"`   // bytes per second
+}
+
+type TaskFile struct {
+	FileName       string `json:"filename"`
+	Size           int64  `json:"size"`
+	SizeDownloaded int64  `json:"size_downloaded"`
+	Priority       string `json:"priority"` // skip, low, normal, high
+}
+
+// Wanted is true if file is selected for download.
+func (tf *TaskFile) Wanted() bool {
+	return tf.Priority != "skip"
+}
+
+type TaskTracker struct {
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	UpdateTimer int64  `json:"update_timer"` // seconds till next update
+	Seeds       int    `json:"seeds"`
+	Peers       int    `json:"peers"`
+}
+
+type TaskPeer struct {
+	Address       string  `json:"address"`
+	Agent         string  `json:"agent"`
+	Progress      float64 `json:"progress"` // [0, 1]
+	SpeedDownload int64   `json:"speed_download"`
+	SpeedUpload   int64   `json:"speed_upload"`
 }
 
 // DownloadStation API. Enhanced by some undocumented API from JS.
@@ -64,7 +194,7 @@ type DownloadStation struct {
 	cl *Client
 }
 
-// List all download tasks in NAS. Implies 'detail' feature. Limit -1 means all.
+// List all download tasks in NAS. Implies 'detail' and 'transfer' features. Limit -1 means all.
 func (ds *DownloadStation) List(ctx context.Context, offset, limit int) (*DownloadTasks, error) {
 	if err := ds.cl.Login(ctx); err != nil {
 		return nil, fmt.Errorf("login: %w", err)
@@ -72,7 +202,7 @@ func (ds *DownloadStation) List(ctx context.Context, offset, limit int) (*Downlo
 	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation.Task`, `list`, []field{
 		{Name: "offset", Value: offset},
 		{Name: "limit", Value: limit},
-		{Name: "additional", Value: "detail"},
+		{Name: "additional", Value: "detail,transfer"},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("call: %w", err)
@@ -87,29 +217,146 @@ func (ds *DownloadStation) List(ctx context.Context, offset, limit int) (*Downlo
 	return &response.Data, nil
 }
 
+// Tasks iterates over all download tasks, requesting pages of pageSize tasks (DefaultPageSize if not positive)
+// until DownloadTasks.Total reached. Tasks already returned by previous pages are skipped,
+// since queue may change between requests. Iteration stops after the first error.
+func (ds *DownloadStation) Tasks(ctx context.Context, pageSize int) iter.Seq2[ScheduledTask, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(ScheduledTask, error) bool) {
+		var seen = make(map[string]bool)
+		var offset int
+		for {
+			page, err := ds.List(ctx, offset, pageSize)
+			if err != nil {
+				yield(ScheduledTask{}, fmt.Errorf("list page at offset %d: %w", offset, err))
+				return
+			}
+			for _, task := range page.Tasks {
+				if seen[task.ID] {
+					continue
+				}
+				seen[task.ID] = true
+				if !yield(task, nil) {
+					return
+				}
+			}
+			offset += len(page.Tasks)
+			if len(page.Tasks) == 0 || int64(offset) >= page.Total {
+				return
+			}
+		}
+	}
+}
+
+// All download tasks in NAS, fetched page by page. See Tasks.
+func (ds *DownloadStation) All(ctx context.Context) ([]ScheduledTask, error) {
+	var ans []ScheduledTask
+	for task, err := range ds.Tasks(ctx, DefaultPageSize) {
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, task)
+	}
+	return ans, nil
+}
+
+// Get tasks by IDs with all additional information: detail, transfer, file, tracker and peer.
+func (ds *DownloadStation) Get(ctx context.Context, ids ...string) ([]ScheduledTask, error) {
+	if err := ds.cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation.Task`, `getinfo`, []field{
+		{Name: "id", Value: strings.Join(ids, ",")},
+		{Name: "additional", Value: "detail,transfer,file,tracker,peer"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call: %w", err)
+	}
+	defer res.Body.Close()
+	var response struct {
+		Data struct {
+			Tasks []ScheduledTask `json:"tasks"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return response.Data.Tasks, nil
+}
+
 // Download remote data from HTTP/FTP/magnet/ED2K links or the file path starting with a shared folder.
 // This is simplified version of Create.
-func (ds *DownloadStation) Download(ctx context.Context, destination string, urls ...string) error {
+func (ds *DownloadStation) Download(ctx context.Context, destination string, urls ...string) ([]string, error) {
 	return ds.Create(ctx, DownloadTask{
 		URL:         urls,
 		Destination: destination,
 	})
 }
 
-// Create download task in DownloadStation based on configuration.
-func (ds *DownloadStation) Create(ctx context.Context, task DownloadTask) error {
+// Create download task in DownloadStation based on configuration. Returns IDs of created tasks.
+//
+// Tasks are created by DownloadStation2 API. Links are created by legacy API if DownloadStation2 API is not
+// available; files require DownloadStation2 API.
+//
+// IDs are returned by Synology, but legacy API and older Download Station versions do not return them. Until
+// the client sees IDs returned, it takes snapshot of tasks list before creation and detects IDs as new tasks in
+// the list after creation (fallback mode). Such creations are serialized within client, however tasks created
+// concurrently by others (ex: RSS or other clients) could be reported too.
+func (ds *DownloadStation) Create(ctx context.Context, task DownloadTask) ([]string, error) {
+	if err := ds.cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	v2, err := ds.useV2(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	if v2 && ds.cl.returnsTaskIDs.Load() {
+		return ds.create(ctx, task)
+	}
+	create := ds.create
+	if !v2 {
+		create = ds.createV1
+	}
+
+	ds.cl.createLock.Lock()
+	if v2 && ds.cl.returnsTaskIDs.Load() { // learned by concurrent creation
+		ds.cl.createLock.Unlock()
+		return ds.create(ctx, task)
+	}
+	defer ds.cl.createLock.Unlock()
+	before, err := ds.taskIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list existing tasks: %w", err)
+	}
+	ids, err := create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		ds.cl.returnsTaskIDs.Store(true)
+		return ids, nil
+	}
+	return ds.createdSince(ctx, before)
+}
+
+// useV2 is true if task should be created by DownloadStation2 API: always for files, for links only if
+// DownloadStation2 API is available.
+func (ds *DownloadStation) useV2(ctx context.Context, task DownloadTask) (bool, error) {
 	if task.File != nil {
-		return ds.createV2(ctx, task)
+		return true, nil
+	}
+	info, err := ds.cl.APIVersion(ctx, `SYNO.DownloadStation2.Task`)
+	if err != nil {
+		return false, fmt.Errorf("get API version: %w", err)
 	}
-	return ds.createV1(ctx, task)
+	return info.MaxVersion > 0, nil
 }
 
-// download task in DownloadStation based on configuration.  Uses Synology legacy API
-func (ds *DownloadStation) createV1(ctx context.Context, task DownloadTask) error {
+// createV1 creates download task from links using legacy API, which does not return IDs of created tasks.
+func (ds *DownloadStation) createV1(ctx context.Context, task DownloadTask) ([]string, error) {
 	slog.Debug("using v1 protocol")
-	if err := ds.cl.Login(ctx); err != nil {
-		return fmt.Errorf("login: %w", err)
-	}
 	var params []field
 	if len(task.URL) > 0 {
 		params = append(params, field{Name: "uri", Value: strings.Join(task.URL, ",")})
@@ -120,32 +367,83 @@ func (ds *DownloadStation) createV1(ctx context.Context, task DownloadTask) erro
 	params = setIfNotEmpty(params, "unzip_password", task.UnzipPassword)
 	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation.Task`, `create`, params)
 	if err != nil {
-		return fmt.Errorf("call API: %w", err)
+		return nil, fmt.Errorf("call API: %w", err)
 	}
-	defer res.Body.Close()
-	return nil
+	_ = res.Body.Close()
+	return nil, nil
 }
 
-// download task in DownloadStation based on configuration. Uses Synology2 API.
-// Applies only for file uploads.
-func (ds *DownloadStation) createV2(ctx context.Context, task DownloadTask) error {
-	slog.Debug("using v2 protocol")
-	if err := ds.cl.Login(ctx); err != nil {
-		return fmt.Errorf("login: %w", err)
+// taskIDs returns set of IDs of all tasks.
+func (ds *DownloadStation) taskIDs(ctx context.Context) (map[string]bool, error) {
+	var ids = make(map[string]bool)
+	for task, err := range ds.Tasks(ctx, DefaultPageSize) {
+		if err != nil {
+			return nil, err
+		}
+		ids[task.ID] = true
 	}
+	return ids, nil
+}
+
+// create download task in DownloadStation based on configuration. Uses DownloadStation2 API for both links and files.
+// Returns IDs of created tasks if Synology returned them.
+func (ds *DownloadStation) create(ctx context.Context, task DownloadTask) ([]string, error) {
 	var params []field
 	params = setIfNotEmpty(params, "username", task.Username)
 	params = setIfNotEmpty(params, "password", task.Password)
 	if task.Destination != "" {
 		value, err := json.Marshal(task.Destination)
 		if err != nil {
-			return fmt.Errorf("marshal destination: %w", err)
+			return nil, fmt.Errorf("marshal destination: %w", err)
 		}
 		params = setIfNotEmpty(params, "destination", string(value))
 	}
 	params = setIfNotEmpty(params, "unzip_password", task.UnzipPassword)
-	params = append(params, field{Name: "type", Value: `"file"`})
 
+	// files list allows to select files before download
+	createList := len(task.Select) > 0
+	params = append(params, field{Name: "create_list", Value: strconv.FormatBool(createList)})
+
+	if task.File != nil {
+		fileParams, err := fileTaskParams(task)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, fileParams...)
+	} else {
+		urls, err := json.Marshal(task.URL)
+		if err != nil {
+			return nil, fmt.Errorf("marshal URLs: %w", err)
+		}
+		params = append(params, field{Name: "type", Value: `"url"`}, field{Name: "url", Value: string(urls)})
+	}
+
+	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation2.Task`, `create`, params)
+	if err != nil {
+		return nil, fmt.Errorf("call API: %w", err)
+	}
+	defer res.Body.Close()
+	var response struct {
+		Data struct {
+			TaskID []string `json:"task_id"`
+			ListID []string `json:"list_id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if !createList {
+		return response.Data.TaskID, nil
+	}
+	if len(response.Data.ListID) == 0 {
+		return nil, ErrNoFilesList
+	}
+	return ds.downloadList(ctx, response.Data.ListID[0], task)
+}
+
+// fileTaskParams returns parameters for uploading file. Detects file type if needed.
+func fileTaskParams(task DownloadTask) ([]field, error) {
+	slog.Debug("uploading file")
 	if task.FileType == FileTypeAuto || task.FileType == FileTypeUnknown {
 		// detect by sniffing the payload (as best as we can; we can just a few)
 		peek := make([]byte, peekSize)
@@ -154,30 +452,189 @@ func (ds *DownloadStation) createV2(ctx context.Context, task DownloadTask) erro
 			err = nil // nps
 		}
 		if err != nil {
-			return fmt.Errorf("peek file: %w", err)
+			return nil, fmt.Errorf("peek file: %w", err)
 		}
 		peek = peek[:n]
 
-		ft, err := detectType(peek)
+		ft, err := DetectFileType(peek)
 		if err != nil {
-			return fmt.Errorf("detect file type: %w", err)
+			return nil, fmt.Errorf("detect file type: %w", err)
 		}
 		slog.Debug("filetype automatically detected", "filetype", ft)
 		task.FileType = ft
 		task.File = io.MultiReader(bytes.NewReader(peek), task.File)
 	}
-	params = append(params, field{Name: "create_list", Value: `false`}) // required
-	params = append(params, field{Name: "file", Value: `["` + task.FileType + `"]`})
-	params = append(params, field{Name: string(task.FileType), Value: fileAttachment{
-		FileName: generateFileName() + "." + string(task.FileType),
-		Reader:   task.File,
-	}})
-	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation2.Task`, `create`, params)
+	return []field{
+		{Name: "type", Value: `"file"`},
+		{Name: "file", Value: `["` + task.FileType + `"]`},
+		{Name: string(task.FileType), Value: fileAttachment{
+			FileName: generateFileName() + "." + string(task.FileType),
+			Reader:   task.File,
+		}},
+	}, nil
+}
+
+// downloadList creates task from files list with selected files only.
+func (ds *DownloadStation) downloadList(ctx context.Context, listID string, task DownloadTask) ([]string, error) {
+	selected, err := json.Marshal(task.Select)
+	if err != nil {
+		return nil, fmt.Errorf("marshal selected files: %w", err)
+	}
+	encodedID, err := json.Marshal(listID)
+	if err != nil {
+		return nil, fmt.Errorf("marshal list ID: %w", err)
+	}
+	params := []field{
+		{Name: "list_id", Value: string(encodedID)},
+		{Name: "selected", Value: string(selected)},
+		{Name: "create_subfolder", Value: "true"},
+	}
+	if task.Destination != "" {
+		value, err := json.Marshal(task.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("marshal destination: %w", err)
+		}
+		params = append(params, field{Name: "destination", Value: string(value)})
+	}
+	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation2.Task.List`, `download`, params)
+	if err != nil {
+		return nil, fmt.Errorf("call API: %w", err)
+	}
+	defer res.Body.Close()
+	var response struct {
+		Data struct {
+			TaskID []string `json:"task_id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return response.Data.TaskID, nil
+}
+
+// createdSince returns IDs of tasks which are not in before set. Creation is asynchronous, so tasks list is
+// polled few times till new tasks appear.
+func (ds *DownloadStation) createdSince(ctx context.Context, before map[string]bool) ([]string, error) {
+	for attempt := 1; ; attempt++ {
+		var ids []string
+		for task, err := range ds.Tasks(ctx, DefaultPageSize) {
+			if err != nil {
+				return nil, fmt.Errorf("list created tasks: %w", err)
+			}
+			if !before[task.ID] {
+				ids = append(ids, task.ID)
+			}
+		}
+		if len(ids) > 0 || attempt >= createdPollAttempts {
+			return ids, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(createdPollInterval):
+		}
+	}
+}
+
+// Wait until all tasks by IDs are completed (see TaskStatus.Completed), polling them with interval.
+// Returns the last known state of tasks. Fails with ErrTaskFailed as soon as any task gets error status and with
+// ErrTaskNotFound if any task disappeared. Use context to limit waiting time.
+func (ds *DownloadStation) Wait(ctx context.Context, interval time.Duration, ids ...string) ([]ScheduledTask, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tasks, err := ds.Get(ctx, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("get tasks: %w", err)
+		}
+		done, err := tasksCompleted(ids, tasks)
+		if err != nil || done {
+			return tasks, err
+		}
+		select {
+		case <-ctx.Done():
+			return tasks, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func tasksCompleted(ids []string, tasks []ScheduledTask) (bool, error) {
+	var done = true
+	for _, id := range ids {
+		idx := slices.IndexFunc(tasks, func(task ScheduledTask) bool { return task.ID == id })
+		if idx < 0 {
+			return false, fmt.Errorf("task %s: %w", id, ErrTaskNotFound)
+		}
+		task := tasks[idx]
+		if task.Status == TaskStatusError {
+			var detail string
+			if task.StatusExtra != nil && task.StatusExtra.ErrorDetail != "" {
+				detail = " (" + task.StatusExtra.ErrorDetail + ")"
+			}
+			return false, fmt.Errorf("task %s %q%s: %w", id, task.Title, detail, ErrTaskFailed)
+		}
+		done = done && task.Status.Completed()
+	}
+	return done, nil
+}
+
+// TaskResult is result of batch operation for single task. Error is Synology error code, 0 means success.
+type TaskResult struct {
+	ID    string `json:"id"`
+	Error int64  `json:"error"`
+}
+
+// Err returns nil for successful operation and RemoteError otherwise.
+func (tr *TaskResult) Err() error {
+	if tr.Error == 0 {
+		return nil
+	}
+	return &RemoteError{Code: tr.Error}
+}
+
+// Pause tasks by IDs.
+func (ds *DownloadStation) Pause(ctx context.Context, ids ...string) ([]TaskResult, error) {
+	return ds.taskBatch(ctx, "pause", ids)
+}
+
+// Resume paused tasks by IDs.
+func (ds *DownloadStation) Resume(ctx context.Context, ids ...string) ([]TaskResult, error) {
+	return ds.taskBatch(ctx, "resume", ids)
+}
+
+// Delete tasks by IDs. If forceComplete set, uncompleted downloaded files will be moved to the destination,
+// otherwise they will be removed.
+func (ds *DownloadStation) Delete(ctx context.Context, forceComplete bool, ids ...string) ([]TaskResult, error) {
+	return ds.taskBatch(ctx, "delete", ids, field{Name: "force_complete", Value: strconv.FormatBool(forceComplete)})
+}
+
+// Edit changes destination (path starting with a shared folder) of tasks by IDs.
+func (ds *DownloadStation) Edit(ctx context.Context, destination string, ids ...string) ([]TaskResult, error) {
+	return ds.taskBatch(ctx, "edit", ids, field{Name: "destination", Value: destination})
+}
+
+// taskBatch calls method for multiple tasks and returns per-task results.
+func (ds *DownloadStation) taskBatch(ctx context.Context, method string, ids []string, params ...field) ([]TaskResult, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := ds.cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	params = append([]field{{Name: "id", Value: strings.Join(ids, ",")}}, params...)
+	res, err := ds.cl.directCall(ctx, `SYNO.DownloadStation.Task`, method, params)
 	if err != nil {
-		return fmt.Errorf("call API: %w", err)
+		return nil, fmt.Errorf("call API: %w", err)
 	}
 	defer res.Body.Close()
-	return nil
+	var response struct {
+		Data []TaskResult `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return response.Data, nil
 }
 
 func setIfNotEmpty(store []field, name string, value string) []field {
@@ -187,11 +644,12 @@ func setIfNotEmpty(store []field, name string, value string) []field {
 	return store
 }
 
-func detectType(peek []byte) (FileType, error) {
-	// it does it's best to detect  but no guarantees.
-
+// DetectFileType guesses type of file by its beginning (at least few hundreds bytes; torrent is detected by "info"
+// dictionary, so beginning should contain it).
+// It does its best to detect, but no guarantees.
+func DetectFileType(peek []byte) (FileType, error) {
 	switch {
-	case bytes.HasPrefix(peek, []byte("d8:announce")):
+	case isTorrent(peek):
 		return FileTypeTorrent, nil
 	case bytes.Contains(peek, []byte("<nzb")) || bytes.Contains(peek, []byte(":nzb")): // targeting xml tag nzb with xmlns or with ns
 		return FileTypeNzb, nil
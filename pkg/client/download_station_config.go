@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// StationInfo is Download Station version and permissions of current user.
+type StationInfo struct {
+	Version       int64  `json:"version"`
+	VersionString string `json:"version_string"`
+	IsManager     bool   `json:"is_manager"`
+}
+
+// StationConfig is global Download Station configuration. Speed limits are in KB/s, 0 means unlimited.
+type StationConfig struct {
+	BTMaxDownload           int64  `json:"bt_max_download"`
+	BTMaxUpload             int64  `json:"bt_max_upload"`
+	EmuleMaxDownload        int64  `json:"emule_max_download"`
+	EmuleMaxUpload          int64  `json:"emule_max_upload"`
+	NZBMaxDownload          int64  `json:"nzb_max_download"`
+	HTTPMaxDownload         int64  `json:"http_max_download"`
+	FTPMaxDownload          int64  `json:"ftp_max_download"`
+	EmuleEnabled            bool   `json:"emule_enabled"`
+	UnzipServiceEnabled     bool   `json:"unzip_service_enabled"`
+	DefaultDestination      string `json:"default_destination"`
+	EmuleDefaultDestination string `json:"emule_default_destination"`
+}
+
+// StationConfigUpdate is partial update of StationConfig. Only set (non-nil) fields are changed.
+type StationConfigUpdate struct {
+	BTMaxDownload           *int64
+	BTMaxUpload             *int64
+	EmuleMaxDownload        *int64
+	EmuleMaxUpload          *int64
+	NZBMaxDownload          *int64
+	HTTPMaxDownload         *int64
+	FTPMaxDownload          *int64
+	EmuleEnabled            *bool
+	UnzipServiceEnabled     *bool
+	DefaultDestination      *string
+	EmuleDefaultDestination *string
+}
+
+// IsEmpty returns true if nothing to update.
+func (scu *StationConfigUpdate) IsEmpty() bool {
+	return len(scu.fields()) == 0
+}
+
+func (scu *StationConfigUpdate) fields() []field {
+	var params []field
+	params = setIfNotNil(params, "bt_max_download", scu.BTMaxDownload)
+	params = setIfNotNil(params, "bt_max_upload", scu.BTMaxUpload)
+	params = setIfNotNil(params, "emule_max_download", scu.EmuleMaxDownload)
+	params = setIfNotNil(params, "emule_max_upload", scu.EmuleMaxUpload)
+	params = setIfNotNil(params, "nzb_max_download", scu.NZBMaxDownload)
+	params = setIfNotNil(params, "http_max_download", scu.HTTPMaxDownload)
+	params = setIfNotNil(params, "ftp_max_download", scu.FTPMaxDownload)
+	params = setIfNotNil(params, "emule_enabled", scu.EmuleEnabled)
+	params = setIfNotNil(params, "unzip_service_enabled", scu.UnzipServiceEnabled)
+	params = setIfNotNil(params, "default_destination", scu.DefaultDestination)
+	params = setIfNotNil(params, "emule_default_destination", scu.EmuleDefaultDestination)
+	return params
+}
+
+// StationSchedule is state of Download Station schedule. Schedule plan itself (hours when downloads are allowed)
+// is not exposed by Web API and configured in DSM UI.
+type StationSchedule struct {
+	Enabled      bool `json:"enabled"`
+	EmuleEnabled bool `json:"emule_enabled"`
+}
+
+// Info returns Download Station version and whether current user is manager.
+func (ds *DownloadStation) Info(ctx context.Context) (*StationInfo, error) {
+	var info StationInfo
+	if err := ds.call(ctx, `SYNO.DownloadStation.Info`, `getinfo`, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Config returns global Download Station configuration.
+func (ds *DownloadStation) Config(ctx context.Context) (*StationConfig, error) {
+	var config StationConfig
+	if err := ds.call(ctx, `SYNO.DownloadStation.Info`, `getconfig`, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SetConfig changes global Download Station configuration. Requires manager permissions.
+func (ds *DownloadStation) SetConfig(ctx context.Context, update StationConfigUpdate) error {
+	params := update.fields()
+	if len(params) == 0 {
+		return nil
+	}
+	return ds.call(ctx, `SYNO.DownloadStation.Info`, `setserverconfig`, params, nil)
+}
+
+// Schedule returns state of Download Station schedule.
+func (ds *DownloadStation) Schedule(ctx context.Context) (*StationSchedule, error) {
+	var schedule StationSchedule
+	if err := ds.call(ctx, `SYNO.DownloadStation.Schedule`, `getconfig`, nil, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// SetSchedule enables or disables Download Station schedule. Requires manager permissions.
+func (ds *DownloadStation) SetSchedule(ctx context.Context, schedule StationSchedule) error {
+	return ds.call(ctx, `SYNO.DownloadStation.Schedule`, `setconfig`, []field{
+		{Name: "enabled", Value: strconv.FormatBool(schedule.Enabled)},
+		{Name: "emule_enabled", Value: strconv.FormatBool(schedule.EmuleEnabled)},
+	}, nil)
+}
+
+// call API method and decode data to out (if not nil).
+func (ds *DownloadStation) call(ctx context.Context, api, method string, params []field, out any) error {
+	if err := ds.cl.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	res, err := ds.cl.directCall(ctx, api, method, params)
+	if err != nil {
+		return fmt.Errorf("call API: %w", err)
+	}
+	defer res.Body.Close()
+	if out == nil {
+		return nil
+	}
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if err := json.Unmarshal(response.Data, out); err != nil {
+		return fmt.Errorf("decode data: %w", err)
+	}
+	return nil
+}
+
+func setIfNotNil[T any](store []field, name string, value *T) []field {
+	if value != nil {
+		return append(store, field{Name: name, Value: *value})
+	}
+	return store
+}
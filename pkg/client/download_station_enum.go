@@ -64,3 +64,135 @@ func ParseFileType(name string) (FileType, error) {
 	}
 	return FileType(""), fmt.Errorf("%s is %w", name, ErrInvalidFileType)
 }
+
+const (
+	// TaskStatusWaiting is a TaskStatus of type Waiting.
+	TaskStatusWaiting TaskStatus = "waiting"
+	// TaskStatusDownloading is a TaskStatus of type Downloading.
+	TaskStatusDownloading TaskStatus = "downloading"
+	// TaskStatusPaused is a TaskStatus of type Paused.
+	TaskStatusPaused TaskStatus = "paused"
+	// TaskStatusFinishing is a TaskStatus of type Finishing.
+	TaskStatusFinishing TaskStatus = "finishing"
+	// TaskStatusFinished is a TaskStatus of type Finished.
+	TaskStatusFinished TaskStatus = "finished"
+	// TaskStatusHashChecking is a TaskStatus of type HashChecking.
+	TaskStatusHashChecking TaskStatus = "hash_checking"
+	// TaskStatusSeeding is a TaskStatus of type Seeding.
+	TaskStatusSeeding TaskStatus = "seeding"
+	// TaskStatusFilehostingWaiting is a TaskStatus of type FilehostingWaiting.
+	TaskStatusFilehostingWaiting TaskStatus = "filehosting_waiting"
+	// TaskStatusExtracting is a TaskStatus of type Extracting.
+	TaskStatusExtracting TaskStatus = "extracting"
+	// TaskStatusError is a TaskStatus of type Error.
+	TaskStatusError TaskStatus = "error"
+)
+
+var ErrInvalidTaskStatus = errors.New("not a valid TaskStatus")
+
+// TaskStatusValues returns a list of the values for TaskStatus
+func TaskStatusValues() []TaskStatus {
+	return []TaskStatus{
+		TaskStatusWaiting,
+		TaskStatusDownloading,
+		TaskStatusPaused,
+		TaskStatusFinishing,
+		TaskStatusFinished,
+		TaskStatusHashChecking,
+		TaskStatusSeeding,
+		TaskStatusFilehostingWaiting,
+		TaskStatusExtracting,
+		TaskStatusError,
+	}
+}
+
+// String implements the Stringer interface.
+func (x TaskStatus) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x TaskStatus) IsValid() bool {
+	_, err := ParseTaskStatus(string(x))
+	return err == nil
+}
+
+var _TaskStatusValue = map[string]TaskStatus{
+	"waiting":             TaskStatusWaiting,
+	"downloading":         TaskStatusDownloading,
+	"paused":              TaskStatusPaused,
+	"finishing":           TaskStatusFinishing,
+	"finished":            TaskStatusFinished,
+	"hash_checking":       TaskStatusHashChecking,
+	"seeding":             TaskStatusSeeding,
+	"filehosting_waiting": TaskStatusFilehostingWaiting,
+	"extracting":          TaskStatusExtracting,
+	"error":               TaskStatusError,
+}
+
+// ParseTaskStatus attempts to convert a string to a TaskStatus.
+func ParseTaskStatus(name string) (TaskStatus, error) {
+	if x, ok := _TaskStatusValue[name]; ok {
+		return x, nil
+	}
+	return TaskStatus(""), fmt.Errorf("%s is %w", name, ErrInvalidTaskStatus)
+}
+
+const (
+	// TaskTypeBt is a TaskType of type Bt.
+	TaskTypeBt TaskType = "bt"
+	// TaskTypeNzb is a TaskType of type Nzb.
+	TaskTypeNzb TaskType = "nzb"
+	// TaskTypeHttp is a TaskType of type Http.
+	TaskTypeHttp TaskType = "http"
+	// TaskTypeHttps is a TaskType of type Https.
+	TaskTypeHttps TaskType = "https"
+	// TaskTypeFtp is a TaskType of type Ftp.
+	TaskTypeFtp TaskType = "ftp"
+	// TaskTypeEmule is a TaskType of type Emule.
+	TaskTypeEmule TaskType = "emule"
+)
+
+var ErrInvalidTaskType = errors.New("not a valid TaskType")
+
+// TaskTypeValues returns a list of the values for TaskType
+func TaskTypeValues() []TaskType {
+	return []TaskType{
+		TaskTypeBt,
+		TaskTypeNzb,
+		TaskTypeHttp,
+		TaskTypeHttps,
+		TaskTypeFtp,
+		TaskTypeEmule,
+	}
+}
+
+// String implements the Stringer interface.
+func (x TaskType) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x TaskType) IsValid() bool {
+	_, err := ParseTaskType(string(x))
+	return err == nil
+}
+
+var _TaskTypeValue = map[string]TaskType{
+	"bt":    TaskTypeBt,
+	"nzb":   TaskTypeNzb,
+	"http":  TaskTypeHttp,
+	"https": TaskTypeHttps,
+	"ftp":   TaskTypeFtp,
+	"emule": TaskTypeEmule,
+}
+
+// ParseTaskType attempts to convert a string to a TaskType.
+func ParseTaskType(name string) (TaskType, error) {
+	if x, ok := _TaskTypeValue[name]; ok {
+		return x, nil
+	}
+	return TaskType(""), fmt.Errorf("%s is %w", name, ErrInvalidTaskType)
+}
@@ -3,13 +3,19 @@ package client_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/reddec/syno-cli/pkg/client"
@@ -28,8 +34,9 @@ func TestClient_Download(t *testing.T) {
 	defer cancel()
 
 	syno := client.New(client.FromEnv(environ()))
-	err := syno.DownloadStation().Download(ctx, "Downloads", `https://webtorrent.io/torrents/cosmos-laundromat.torrent`)
+	ids, err := syno.DownloadStation().Download(ctx, "Downloads", `https://webtorrent.io/torrents/cosmos-laundromat.torrent`)
 	require.NoError(t, err)
+	require.NotEmpty(t, ids)
 }
 
 func TestClient_TorrentFile(t *testing.T) {
@@ -55,12 +62,13 @@ func TestClient_TorrentFile(t *testing.T) {
 	require.NoError(t, err)
 
 	syno := client.New(client.FromEnv(environ()))
-	err = syno.DownloadStation().Create(ctx,
+	ids, err := syno.DownloadStation().Create(ctx,
 		client.DownloadTask{
 			File:        bytes.NewReader(torrentFile),
 			Destination: "Downloads",
 		})
 	require.NoError(t, err)
+	require.NotEmpty(t, ids)
 }
 
 func TestDownloadStation_List(t *testing.T) {
@@ -74,3 +82,480 @@ func TestDownloadStation_List(t *testing.T) {
 
 	t.Logf("%+v", *list)
 }
+
+func TestTaskResult_Err(t *testing.T) {
+	ok := client.TaskResult{ID: "dbid_1"}
+	require.NoError(t, ok.Err())
+
+	failed := client.TaskResult{ID: "dbid_2", Error: 405}
+	var remote *client.RemoteError
+	require.ErrorAs(t, failed.Err(), &remote)
+	require.Equal(t, int64(405), remote.Code)
+}
+
+func TestDownloadStation_taskBatch(t *testing.T) {
+	type call struct {
+		Method string
+		IDs    string
+		Extra  string
+	}
+	var calls []call
+	var unexpected []string
+	srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		if api := request.FormValue("api"); api != "SYNO.DownloadStation.Task" {
+			unexpected = append(unexpected, api+" "+request.FormValue("method"))
+			return httpStatus(http.StatusInternalServerError)
+		}
+		c := call{Method: request.FormValue("method"), IDs: request.FormValue("id")}
+		switch c.Method {
+		case "delete":
+			c.Extra = request.FormValue("force_complete")
+		case "edit":
+			c.Extra = request.FormValue("destination")
+		}
+		calls = append(calls, c)
+		var results []client.TaskResult
+		for _, id := range strings.Split(c.IDs, ",") {
+			var code int64
+			if id == "dbid_missing" {
+				code = 544
+			}
+			results = append(results, client.TaskResult{ID: id, Error: code})
+		}
+		return results
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+	results, err := ds.Pause(ctx, "dbid_1", "dbid_missing", "dbid_2")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err())
+	require.NoError(t, results[2].Err())
+	var remote *client.RemoteError
+	require.ErrorAs(t, results[1].Err(), &remote)
+	require.Equal(t, int64(544), remote.Code)
+	require.Equal(t, "dbid_missing", results[1].ID)
+
+	_, err = ds.Resume(ctx, "dbid_1")
+	require.NoError(t, err)
+	_, err = ds.Delete(ctx, true, "dbid_1", "dbid_2")
+	require.NoError(t, err)
+	_, err = ds.Edit(ctx, "video/movies", "dbid_2")
+	require.NoError(t, err)
+
+	results, err = ds.Pause(ctx) // nothing to do
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	require.Equal(t, []call{
+		{Method: "pause", IDs: "dbid_1,dbid_missing,dbid_2"}, // all IDs in one request
+		{Method: "resume", IDs: "dbid_1"},
+		{Method: "delete", IDs: "dbid_1,dbid_2", Extra: "true"},
+		{Method: "edit", IDs: "dbid_2", Extra: "video/movies"},
+	}, calls)
+	require.Empty(t, unexpected)
+}
+
+func TestDownloadStation_taskBatch_failed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		if request.URL.Path == "/webapi/query.cgi" {
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": true, "data": map[string]client.API{
+				"SYNO.API.Auth":             {MaxVersion: 6, Path: "entry.cgi"},
+				"SYNO.DownloadStation.Task": {MaxVersion: 1, Path: "DownloadStation/task.cgi"},
+			}})
+			return
+		}
+		if request.FormValue("api") == "SYNO.API.Auth" {
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": true, "data": map[string]string{"sid": "test"}})
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]any{"success": false, "error": map[string]any{"code": 105}})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+	_, err := ds.Delete(ctx, false, "dbid_1")
+	var remote *client.RemoteError
+	require.ErrorAs(t, err, &remote)
+	require.Equal(t, int64(105), remote.Code)
+}
+
+func TestScheduledTask_Progress(t *testing.T) {
+	const payload = `{
+		"id": "dbid_1",
+		"size": 1000,
+		"status": "downloading",
+		"additional": {
+			"transfer": {"size_downloaded": 250, "size_uploaded": 10, "speed_download": 50, "speed_upload": 1},
+			"file": [{"filename": "a.iso", "size": 1000, "size_downloaded": 250, "priority": "normal"}, {"filename": "b.txt", "size": 1, "priority": "skip"}],
+			"tracker": [{"url": "udp://tracker", "status": "Success", "seeds": 3, "peers": 4}],
+			"peer": [{"address": "10.0.0.1:6881", "agent": "qBittorrent", "progress": 0.5, "speed_download": 50}]
+		}
+	}`
+	var task client.ScheduledTask
+	require.NoError(t, json.Unmarshal([]byte(payload), &task))
+
+	require.InDelta(t, 0.25, task.Progress(), 0.0001)
+	eta, ok := task.ETA()
+	require.True(t, ok)
+	require.Equal(t, 15*time.Second, eta)
+
+	require.Len(t, task.Additional.File, 2)
+	require.True(t, task.Additional.File[0].Wanted())
+	require.False(t, task.Additional.File[1].Wanted())
+	require.Equal(t, 3, task.Additional.Tracker[0].Seeds)
+	require.Equal(t, "qBittorrent", task.Additional.Peer[0].Agent)
+
+	task.Additional.Transfer.SpeedDownload = 0
+	_, ok = task.ETA()
+	require.False(t, ok)
+}
+
+func TestTaskFilter_Match(t *testing.T) {
+	task := client.ScheduledTask{
+		ID:       "dbid_1",
+		Type:     client.TaskTypeBt,
+		Username: "admin",
+		Title:    "ubuntu-24.04.iso",
+		Status:   client.TaskStatusSeeding,
+	}
+
+	require.True(t, (&client.TaskFilter{}).Match(&task))
+	require.True(t, (&client.TaskFilter{Status: []client.TaskStatus{client.TaskStatusError, client.TaskStatusSeeding}}).Match(&task))
+	require.False(t, (&client.TaskFilter{Status: []client.TaskStatus{client.TaskStatusPaused}}).Match(&task))
+	require.True(t, (&client.TaskFilter{Type: []client.TaskType{client.TaskTypeBt}, User: []string{"admin"}}).Match(&task))
+	require.False(t, (&client.TaskFilter{Type: []client.TaskType{client.TaskTypeHttps}}).Match(&task))
+	require.False(t, (&client.TaskFilter{User: []string{"guest"}}).Match(&task))
+	require.True(t, (&client.TaskFilter{Title: "*.iso"}).Match(&task))
+	require.False(t, (&client.TaskFilter{Title: "*.mkv"}).Match(&task))
+
+	status, err := client.ParseTaskStatus("hash_checking")
+	require.NoError(t, err)
+	require.Equal(t, client.TaskStatusHashChecking, status)
+	_, err = client.ParseTaskStatus("unknown")
+	require.ErrorIs(t, err, client.ErrInvalidTaskStatus)
+}
+
+func TestDownloadStation_All(t *testing.T) {
+	var tasks []client.ScheduledTask
+	for i := range 250 {
+		tasks = append(tasks, client.ScheduledTask{ID: "dbid_" + strconv.Itoa(i), Status: client.TaskStatusWaiting})
+	}
+	var pages int
+	var unexpected []string
+	srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		if api, method := request.FormValue("api"), request.FormValue("method"); api != "SYNO.DownloadStation.Task" || method != "list" {
+			unexpected = append(unexpected, api+" "+method)
+			return httpStatus(http.StatusInternalServerError)
+		}
+		offset, _ := strconv.Atoi(request.FormValue("offset"))
+		limit, _ := strconv.Atoi(request.FormValue("limit"))
+		pages++
+		return client.DownloadTasks{Total: int64(len(tasks)), Offset: int64(offset), Tasks: tasks[offset:min(offset+limit, len(tasks))]}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	syno := client.New(client.Config{URL: srv.URL})
+	all, err := syno.DownloadStation().All(ctx)
+	require.NoError(t, err)
+	require.Equal(t, tasks, all)
+	require.Equal(t, 3, pages)
+
+	// early break should stop paging
+	pages = 0
+	var n int
+	for task, err := range syno.DownloadStation().Tasks(ctx, 10) {
+		require.NoError(t, err)
+		require.Equal(t, tasks[n].ID, task.ID)
+		n++
+		if n == 15 {
+			break
+		}
+	}
+	require.Equal(t, 2, pages)
+	require.Empty(t, unexpected)
+}
+
+func TestDownloadStation_Create(t *testing.T) {
+	var lists int
+	var unexpected []string
+	srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		switch api, method := request.FormValue("api"), request.FormValue("method"); {
+		case api == "SYNO.DownloadStation2.Task" && method == "create" && request.FormValue("create_list") == "true":
+			return map[string]any{"list_id": []string{"list_1"}, "task_id": []string{}}
+		case api == "SYNO.DownloadStation2.Task" && method == "create" && request.FormValue("type") == `"url"`:
+			assert.Equal(t, `["https://example.com/file.iso"]`, request.FormValue("url"))
+			assert.Equal(t, `"Downloads"`, request.FormValue("destination"))
+			return map[string]any{"list_id": []string{}, "task_id": []string{"dbid_2"}}
+		case api == "SYNO.DownloadStation2.Task" && method == "create":
+			assert.Equal(t, `"file"`, request.FormValue("type"))
+			return map[string]any{"list_id": []string{}, "task_id": []string{"dbid_10"}}
+		case api == "SYNO.DownloadStation2.Task.List" && method == "download":
+			assert.Equal(t, `"list_1"`, request.FormValue("list_id"))
+			assert.Equal(t, `[1]`, request.FormValue("selected"))
+			return map[string]any{"task_id": []string{"dbid_3"}}
+		case api == "SYNO.DownloadStation.Task" && method == "list":
+			lists++ // snapshot till IDs are returned
+			return client.DownloadTasks{}
+		}
+		unexpected = append(unexpected, request.FormValue("api")+" "+request.FormValue("method"))
+		return httpStatus(http.StatusInternalServerError)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+	ids, err := ds.Create(ctx, client.DownloadTask{File: strings.NewReader("d8:announce3:urle"), FileType: client.FileTypeTorrent})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dbid_10"}, ids)
+
+	ids, err = ds.Download(ctx, "Downloads", "https://example.com/file.iso")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dbid_2"}, ids)
+
+	ids, err = ds.Create(ctx, client.DownloadTask{File: strings.NewReader("d8:announce3:urle"), FileType: client.FileTypeTorrent, Select: []int{1}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dbid_3"}, ids)
+	require.Equal(t, 1, lists)
+	require.Empty(t, unexpected)
+}
+
+func TestDownloadStation_Create_noTaskIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		apis map[string]client.API
+		api  string
+	}{
+		{name: "v2 without IDs", apis: defaultAPIs, api: "SYNO.DownloadStation2.Task"},
+		{name: "v1", apis: withoutAPIs(defaultAPIs, "SYNO.DownloadStation2.Task", "SYNO.DownloadStation2.Task.List"), api: "SYNO.DownloadStation.Task"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tasks = []client.ScheduledTask{{ID: "dbid_1"}}
+			var lists int
+			var unexpected []string
+			srv := fakeSynologyAPIs(t, tt.apis, func(writer http.ResponseWriter, request *http.Request) any {
+				switch api, method := request.FormValue("api"), request.FormValue("method"); {
+				case api == tt.api && method == "create":
+					if api == "SYNO.DownloadStation.Task" {
+						assert.Equal(t, "https://example.com/a.iso,https://example.com/b.iso", request.FormValue("uri"))
+					}
+					tasks = append(tasks, client.ScheduledTask{ID: "dbid_" + strconv.Itoa(len(tasks)+1)})
+					return map[string]any{}
+				case api == "SYNO.DownloadStation.Task" && method == "list":
+					lists++
+					return client.DownloadTasks{Total: int64(len(tasks)), Tasks: tasks}
+				}
+				unexpected = append(unexpected, request.FormValue("api")+" "+request.FormValue("method"))
+				return httpStatus(http.StatusInternalServerError)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+			// each creation detects IDs by tasks list, including the first one
+			ids, err := ds.Create(ctx, client.DownloadTask{URL: []string{"https://example.com/a.iso", "https://example.com/b.iso"}})
+			require.NoError(t, err)
+			require.Equal(t, []string{"dbid_2"}, ids)
+			require.Equal(t, 2, lists)
+
+			ids, err = ds.Create(ctx, client.DownloadTask{URL: []string{"https://example.com/a.iso", "https://example.com/b.iso"}})
+			require.NoError(t, err)
+			require.Equal(t, []string{"dbid_3"}, ids)
+			require.Equal(t, 4, lists)
+			require.Empty(t, unexpected)
+		})
+	}
+}
+
+func TestDownloadStation_Config(t *testing.T) {
+	var config = map[string]any{"bt_max_download": 0, "bt_max_upload": 100, "emule_enabled": false, "default_destination": "Downloads"}
+	var schedule = map[string]any{"enabled": false, "emule_enabled": false}
+	var unexpected []string
+	srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		switch api, method := request.FormValue("api"), request.FormValue("method"); {
+		case api == "SYNO.DownloadStation.Info" && method == "getinfo":
+			return map[string]any{"version": 4000, "version_string": "4.0.0-4000", "is_manager": true}
+		case api == "SYNO.DownloadStation.Info" && method == "getconfig":
+			return config
+		case api == "SYNO.DownloadStation.Info" && method == "setserverconfig":
+			assert.Empty(t, request.FormValue("bt_max_upload"))
+			config["bt_max_download"], _ = strconv.Atoi(request.FormValue("bt_max_download"))
+			config["emule_enabled"] = request.FormValue("emule_enabled") == "true"
+			return nil
+		case api == "SYNO.DownloadStation.Schedule" && method == "getconfig":
+			return schedule
+		case api == "SYNO.DownloadStation.Schedule" && method == "setconfig":
+			schedule["enabled"] = request.FormValue("enabled") == "true"
+			return nil
+		}
+		unexpected = append(unexpected, request.FormValue("api")+" "+request.FormValue("method"))
+		return httpStatus(http.StatusInternalServerError)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+	info, err := ds.Info(ctx)
+	require.NoError(t, err)
+	require.True(t, info.IsManager)
+	require.Equal(t, "4.0.0-4000", info.VersionString)
+
+	down, emule := int64(5120), true
+	require.NoError(t, ds.SetConfig(ctx, client.StationConfigUpdate{BTMaxDownload: &down, EmuleEnabled: &emule}))
+	cfg, err := ds.Config(ctx)
+	require.NoError(t, err)
+	require.Equal(t, down, cfg.BTMaxDownload)
+	require.Equal(t, int64(100), cfg.BTMaxUpload)
+	require.True(t, cfg.EmuleEnabled)
+	require.Equal(t, "Downloads", cfg.DefaultDestination)
+
+	require.NoError(t, ds.SetSchedule(ctx, client.StationSchedule{Enabled: true}))
+	sched, err := ds.Schedule(ctx)
+	require.NoError(t, err)
+	require.True(t, sched.Enabled)
+	require.Empty(t, unexpected)
+}
+
+//nolint:gochecknoglobals
+var defaultAPIs = map[string]client.API{
+	"SYNO.API.Auth":                   {MaxVersion: 6, Path: "entry.cgi"},
+	"SYNO.DownloadStation.Task":       {MaxVersion: 1, Path: "DownloadStation/task.cgi"},
+	"SYNO.DownloadStation2.Task":      {MaxVersion: 2, Path: "entry.cgi"},
+	"SYNO.DownloadStation2.Task.List": {MaxVersion: 2, Path: "entry.cgi"},
+	"SYNO.DownloadStation.Info":       {MaxVersion: 2, Path: "DownloadStation/info.cgi"},
+	"SYNO.DownloadStation.Schedule":   {MaxVersion: 1, Path: "DownloadStation/schedule.cgi"},
+	"SYNO.Core.Certificate":           {MaxVersion: 1, Path: "entry.cgi"},
+	"SYNO.Core.Certificate.CRT":       {MaxVersion: 1, Path: "entry.cgi"},
+	"SYNO.Core.DSMNotify":             {MaxVersion: 1, Path: "entry.cgi"},
+	"SYNO.FileStation.List":           {MaxVersion: 2, Path: "entry.cgi"},
+	"SYNO.FileStation.Upload":         {MaxVersion: 2, Path: "entry.cgi"},
+	"SYNO.FileStation.Download":       {MaxVersion: 2, Path: "entry.cgi"},
+	"SYNO.FileStation.Delete":         {MaxVersion: 2, Path: "entry.cgi"},
+}
+
+// withoutAPIs returns copy of APIs without specified names.
+func withoutAPIs(apis map[string]client.API, names ...string) map[string]client.API {
+	var ans = make(map[string]client.API, len(apis))
+	for name, api := range apis {
+		if !slices.Contains(names, name) {
+			ans[name] = api
+		}
+	}
+	return ans
+}
+
+// fakeSynology serves API info, login and wraps result of handler as successful API response.
+// Handler may return apiErrorCode for failed response, rawContent for non-JSON response or httpStatus.
+// Handler runs in server goroutine, so it should not stop test (t.Fatal, require).
+func fakeSynology(t *testing.T, handler func(writer http.ResponseWriter, request *http.Request) any) *httptest.Server {
+	t.Helper()
+	return fakeSynologyAPIs(t, defaultAPIs, handler)
+}
+
+// fakeSynologyAPIs is fakeSynology with custom set of available APIs.
+func fakeSynologyAPIs(t *testing.T, apis map[string]client.API, handler func(writer http.ResponseWriter, request *http.Request) any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var data any
+		switch {
+		case request.URL.Path == "/webapi/query.cgi":
+			data = apis
+		case request.FormValue("api") == "SYNO.API.Auth":
+			data = map[string]string{"sid": "test"}
+		default:
+			data = handler(writer, request)
+		}
+		switch data := data.(type) {
+		case httpStatus:
+			writer.WriteHeader(int(data))
+		case apiErrorCode:
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": false, "error": map[string]any{"code": data}})
+		case rawContent:
+			writer.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = writer.Write(data)
+		default:
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(map[string]any{"success": true, "data": data})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// apiErrorCode is returned by fakeSynology handler to respond with API error.
+type apiErrorCode int
+
+// rawContent is returned by fakeSynology handler to respond with file content.
+type rawContent []byte
+
+// httpStatus is returned by fakeSynology handler to respond with HTTP status without API response,
+// ex: for unexpected calls, which are recorded by handler and checked by test.
+type httpStatus int
+
+func TestDownloadStation_Wait(t *testing.T) {
+	var statuses = map[string][]client.TaskStatus{
+		"dbid_1": {client.TaskStatusWaiting, client.TaskStatusDownloading, client.TaskStatusSeeding},
+		"dbid_2": {client.TaskStatusDownloading, client.TaskStatusError},
+	}
+	var polls int
+	var unexpected []string
+	srv := fakeSynology(t, func(writer http.ResponseWriter, request *http.Request) any {
+		if method := request.FormValue("method"); method != "getinfo" {
+			unexpected = append(unexpected, request.FormValue("api")+" "+method)
+			return httpStatus(http.StatusInternalServerError)
+		}
+		var tasks []client.ScheduledTask
+		for _, id := range strings.Split(request.FormValue("id"), ",") {
+			seq, ok := statuses[id]
+			if !ok {
+				continue
+			}
+			task := client.ScheduledTask{ID: id, Title: id, Status: seq[min(polls, len(seq)-1)]}
+			if task.Status == client.TaskStatusError {
+				task.StatusExtra = &client.TaskStatusExtra{ErrorDetail: "broken_link"}
+			}
+			tasks = append(tasks, task)
+		}
+		polls++
+		return map[string]any{"tasks": tasks}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ds := client.New(client.Config{URL: srv.URL}).DownloadStation()
+
+	tasks, err := ds.Wait(ctx, time.Millisecond, "dbid_1")
+	require.NoError(t, err)
+	require.Equal(t, client.TaskStatusSeeding, tasks[0].Status)
+	require.Equal(t, 3, polls)
+
+	polls = 0
+	_, err = ds.Wait(ctx, time.Millisecond, "dbid_1", "dbid_2")
+	require.ErrorIs(t, err, client.ErrTaskFailed)
+	require.ErrorContains(t, err, "broken_link")
+
+	polls = 0
+	_, err = ds.Wait(ctx, time.Millisecond, "dbid_1", "dbid_3")
+	require.ErrorIs(t, err, client.ErrTaskNotFound)
+
+	polls = 0
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelShort()
+	_, err = ds.Wait(short, time.Hour, "dbid_1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, unexpected)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// File Station error codes.
+// See https://global.download.synology.com/download/Document/Software/DeveloperGuide/Firmware/DSM/All/enu/Synology_File_Station_API_Guide.pdf
+const (
+	fsErrNoSuchFile = 408
+)
+
+type FileInfo struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	IsDir bool   `json:"isdir"`
+}
+
+// FileStation API. Only basic operations required for storing small files are supported.
+type FileStation struct {
+	cl *Client
+}
+
+// FileStation API
+func (cl *Client) FileStation() *FileStation {
+	return &FileStation{cl: cl}
+}
+
+// Upload file to the specified path (starting with shared folder, ex: /homes/admin/file.txt).
+// Parent directories are created automatically. Existent file will be overwritten.
+func (fst *FileStation) Upload(ctx context.Context, filePath string, content io.Reader) error {
+	if err := fst.cl.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	dir, name := path.Split(filePath)
+	res, err := fst.cl.directCall(ctx, "SYNO.FileStation.Upload", "upload", []field{
+		{Name: "path", Value: path.Clean(dir)},
+		{Name: "create_parents", Value: "true"},
+		{Name: "overwrite", Value: "true"},
+		{Name: "file", Value: fileAttachment{FileName: name, Reader: content}}, // should be last
+	})
+	if err != nil {
+		return fmt.Errorf("call API: %w", err)
+	}
+	defer res.Body.Close()
+	return nil
+}
+
+// Download file by path (starting with shared folder). Caller must close stream.
+// Returns fs.ErrNotExist if file not found.
+func (fst *FileStation) Download(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	if err := fst.cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	paths, err := json.Marshal([]string{filePath})
+	if err != nil {
+		return nil, fmt.Errorf("marshal path: %w", err)
+	}
+	res, err := fst.cl.rawCall(ctx, "SYNO.FileStation.Download", "download", []field{
+		{Name: "path", Value: string(paths)},
+		{Name: "mode", Value: "download"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call API: %w", notExist(err))
+	}
+	return res.Body, nil
+}
+
+// List files in folder (starting with shared folder). Returns fs.ErrNotExist if folder not found.
+func (fst *FileStation) List(ctx context.Context, folder string) ([]FileInfo, error) {
+	if err := fst.cl.Login(ctx); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	res, err := fst.cl.directCall(ctx, "SYNO.FileStation.List", "list", []field{
+		{Name: "folder_path", Value: folder},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call API: %w", notExist(err))
+	}
+	defer res.Body.Close()
+	var response struct {
+		Data struct {
+			Files []FileInfo `json:"files"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return response.Data.Files, nil
+}
+
+// Delete file or folder (recursively) by path (starting with shared folder). Blocks till operation completed.
+func (fst *FileStation) Delete(ctx context.Context, filePath string) error {
+	if err := fst.cl.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	paths, err := json.Marshal([]string{filePath})
+	if err != nil {
+		return fmt.Errorf("marshal path: %w", err)
+	}
+	res, err := fst.cl.directCall(ctx, "SYNO.FileStation.Delete", "delete", []field{
+		{Name: "path", Value: string(paths)},
+		{Name: "recursive", Value: "true"},
+	})
+	if err != nil {
+		return fmt.Errorf("call API: %w", notExist(err))
+	}
+	defer res.Body.Close()
+	return nil
+}
+
+// notExist wraps fs.ErrNotExist if error is File Station not found error.
+func notExist(err error) error {
+	var remote *RemoteError
+	if errors.As(err, &remote) && remote.Code == fsErrNoSuchFile {
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return err
+}
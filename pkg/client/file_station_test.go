@@ -0,0 +1,100 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestFileStation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var files = make(map[string]string)
+	var unexpected []string
+	srv := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+		var paths []string
+		_ = json.Unmarshal([]byte(request.FormValue("path")), &paths)
+		switch request.FormValue("api") {
+		case "SYNO.FileStation.Upload":
+			assert.Equal(t, "upload", request.FormValue("method"))
+			assert.Equal(t, "true", request.FormValue("create_parents"))
+			assert.Equal(t, "true", request.FormValue("overwrite"))
+			file, header, err := request.FormFile("file")
+			if !assert.NoError(t, err) {
+				return apiErrorCode(101)
+			}
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			files[request.FormValue("path")+"/"+header.Filename] = string(content)
+			return nil
+		case "SYNO.FileStation.Download":
+			assert.Equal(t, "download", request.FormValue("mode"))
+			content, ok := files[paths[0]]
+			if !ok {
+				return apiErrorCode(408)
+			}
+			return rawContent(content)
+		case "SYNO.FileStation.List":
+			folder := request.FormValue("folder_path")
+			var list []client.FileInfo
+			for name := range files {
+				if rel, ok := strings.CutPrefix(name, folder+"/"); ok && !strings.Contains(rel, "/") {
+					list = append(list, client.FileInfo{Name: rel, Path: name})
+				}
+			}
+			if len(list) == 0 {
+				return apiErrorCode(408)
+			}
+			return map[string]any{"files": list}
+		case "SYNO.FileStation.Delete":
+			assert.Equal(t, "true", request.FormValue("recursive"))
+			if _, ok := files[paths[0]]; !ok {
+				return apiErrorCode(408)
+			}
+			delete(files, paths[0])
+			return nil
+		default:
+			unexpected = append(unexpected, request.FormValue("api")+" "+request.FormValue("method"))
+			return httpStatus(http.StatusInternalServerError)
+		}
+	})
+	fst := client.New(client.Config{URL: srv.URL}).FileStation()
+
+	require.NoError(t, fst.Upload(ctx, "/homes/admin/cache/example.json", strings.NewReader("first")))
+	require.NoError(t, fst.Upload(ctx, "/homes/admin/cache/example.json", strings.NewReader("second")))
+	assert.Equal(t, map[string]string{"/homes/admin/cache/example.json": "second"}, files)
+
+	stream, err := fst.Download(ctx, "/homes/admin/cache/example.json")
+	require.NoError(t, err)
+	content, err := io.ReadAll(stream)
+	require.NoError(t, stream.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	list, err := fst.List(ctx, "/homes/admin/cache")
+	require.NoError(t, err)
+	assert.Equal(t, []client.FileInfo{{Name: "example.json", Path: "/homes/admin/cache/example.json"}}, list)
+
+	require.NoError(t, fst.Delete(ctx, "/homes/admin/cache/example.json"))
+	assert.Empty(t, files)
+
+	_, err = fst.Download(ctx, "/homes/admin/cache/example.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = fst.List(ctx, "/homes/admin/cache")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	err = fst.Delete(ctx, "/homes/admin/cache/example.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Empty(t, unexpected)
+}
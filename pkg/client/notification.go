@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Notification is message shown in DSM notification center.
+type Notification struct {
+	Title   string
+	Message string
+	// Recipients are users or groups with @ prefix (ex: admin, @administrators).
+	Recipients []string
+}
+
+// Notify shows notification in DSM notification center of recipients.
+func (cl *Client) Notify(ctx context.Context, notification Notification) error {
+	if err := cl.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	recipients, err := json.Marshal(notification.Recipients)
+	if err != nil {
+		return fmt.Errorf("marshal recipients: %w", err)
+	}
+	res, err := cl.directCall(ctx, "SYNO.Core.DSMNotify", "notify", []field{
+		{Name: "action", Value: "apply"},
+		{Name: "recipients", Value: recipients},
+		{Name: "title", Value: notification.Title},
+		{Name: "msg", Value: notification.Message},
+	})
+	if err != nil {
+		return fmt.Errorf("call API: %w", err)
+	}
+	defer res.Body.Close()
+	return nil
+}
@@ -0,0 +1,37 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+func TestClient_Notify(t *testing.T) {
+	var calls []string
+	srv := fakeSynology(t, func(_ http.ResponseWriter, request *http.Request) any {
+		calls = append(calls, request.FormValue("api")+" "+request.FormValue("method"))
+		assert.Equal(t, `["@administrators"]`, request.FormValue("recipients"))
+		assert.Equal(t, "title", request.FormValue("title"))
+		if request.FormValue("msg") == "broken" {
+			return apiErrorCode(103)
+		}
+		assert.Equal(t, "message", request.FormValue("msg"))
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cl := client.New(client.Config{URL: srv.URL})
+
+	require.NoError(t, cl.Notify(ctx, client.Notification{Title: "title", Message: "message", Recipients: []string{"@administrators"}}))
+	var remote *client.RemoteError
+	require.ErrorAs(t, cl.Notify(ctx, client.Notification{Title: "title", Message: "broken", Recipients: []string{"@administrators"}}), &remote)
+	assert.Equal(t, int64(103), remote.Code)
+	assert.Equal(t, []string{"SYNO.Core.DSMNotify notify", "SYNO.Core.DSMNotify notify"}, calls)
+}
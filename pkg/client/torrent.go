@@ -0,0 +1,375 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // info-hash v1 is defined as SHA-1
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidBencode = errors.New("invalid bencode")
+	ErrInvalidTorrent = errors.New("invalid torrent")
+	ErrInvalidMagnet  = errors.New("invalid magnet link")
+)
+
+const maxBencodeDepth = 64
+
+// TorrentInfo is metadata of torrent file or magnet link.
+type TorrentInfo struct {
+	Name     string        // suggested name of file or directory
+	InfoHash string        // info-hash v1, lower-case hex
+	Size     int64         // total size, unknown (0) for magnet link without xl parameter
+	Files    []TorrentFile // files in torrent order, empty for magnet links
+	Trackers []string      // announce URLs without duplicates
+}
+
+// TorrentFile is single file in torrent.
+type TorrentFile struct {
+	Path string // path relative to torrent directory; for single-file torrent it's the name of torrent
+	Size int64
+}
+
+// Select returns indexes of files matched by any selector: file index or glob (see path.Match) by path or base name.
+// Selector which matched nothing is an error.
+func (ti *TorrentInfo) Select(selectors ...string) ([]int, error) {
+	var selected = make([]bool, len(ti.Files))
+	for _, selector := range selectors {
+		if _, err := path.Match(selector, ""); err != nil {
+			return nil, fmt.Errorf("selector %q: %w", selector, err)
+		}
+		var found bool
+		if idx, err := strconv.Atoi(selector); err == nil && idx >= 0 && idx < len(ti.Files) {
+			selected[idx] = true
+			found = true
+		}
+		for i, file := range ti.Files {
+			matchPath, _ := path.Match(selector, file.Path)
+			matchName, _ := path.Match(selector, path.Base(file.Path))
+			if matchPath || matchName {
+				selected[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("selector %q matched no files", selector) //nolint:goerr113
+		}
+	}
+	var ans []int
+	for i, ok := range selected {
+		if ok {
+			ans = append(ans, i)
+		}
+	}
+	return ans, nil
+}
+
+// ParseTorrent parses and validates torrent file (BEP 3). Only torrents with v1 metadata (including hybrid) are
+// supported.
+func ParseTorrent(data []byte) (*TorrentInfo, error) {
+	dec := &bencodeDecoder{data: data}
+	value, err := dec.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if dec.pos != len(data) {
+		return nil, fmt.Errorf("%w: trailing data at %d", ErrInvalidBencode, dec.pos)
+	}
+	root, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: root is not dictionary", ErrInvalidTorrent)
+	}
+	info, ok := root["info"].(map[string]any)
+	if !ok || dec.info == nil {
+		return nil, fmt.Errorf("%w: info dictionary not found", ErrInvalidTorrent)
+	}
+
+	var ans TorrentInfo
+	hash := sha1.Sum(dec.info) //nolint:gosec
+	ans.InfoHash = hex.EncodeToString(hash[:])
+	ans.Name = bencodeString(info, "name.utf-8", "name")
+	if ans.Name == "" {
+		return nil, fmt.Errorf("%w: name not set", ErrInvalidTorrent)
+	}
+	if pieceLength, ok := info["piece length"].(int64); !ok || pieceLength <= 0 {
+		return nil, fmt.Errorf("%w: invalid piece length", ErrInvalidTorrent)
+	}
+	if pieces, ok := info["pieces"].(string); !ok || len(pieces) == 0 || len(pieces)%sha1.Size != 0 {
+		return nil, fmt.Errorf("%w: invalid pieces", ErrInvalidTorrent)
+	}
+
+	if files, ok := info["files"].([]any); ok {
+		for i, item := range files {
+			file, err := parseTorrentFile(item)
+			if err != nil {
+				return nil, fmt.Errorf("%w: file #%d: %w", ErrInvalidTorrent, i, err)
+			}
+			ans.Files = append(ans.Files, file)
+			ans.Size += file.Size
+		}
+	} else if length, ok := info["length"].(int64); ok && length >= 0 {
+		ans.Files = []TorrentFile{{Path: ans.Name, Size: length}}
+		ans.Size = length
+	} else {
+		return nil, fmt.Errorf("%w: neither length nor files set", ErrInvalidTorrent)
+	}
+
+	if announce, ok := root["announce"].(string); ok {
+		ans.addTracker(announce)
+	}
+	if tiers, ok := root["announce-list"].([]any); ok {
+		for _, tier := range tiers {
+			trackers, _ := tier.([]any)
+			for _, tracker := range trackers {
+				if announce, ok := tracker.(string); ok {
+					ans.addTracker(announce)
+				}
+			}
+		}
+	}
+	return &ans, nil
+}
+
+// ParseMagnet parses magnet link (BEP 9). Info-hash (xt=urn:btih) is required and could be hex or base32 encoded.
+// Name (dn), size (xl) and trackers (tr) are optional.
+func ParseMagnet(uri string) (*TorrentInfo, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMagnet, err)
+	}
+	if u.Scheme != "magnet" {
+		return nil, fmt.Errorf("%w: scheme %q", ErrInvalidMagnet, u.Scheme)
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMagnet, err)
+	}
+	var ans TorrentInfo
+	for _, xt := range query["xt"] {
+		hash, ok := strings.CutPrefix(xt, "urn:btih:")
+		if !ok {
+			continue
+		}
+		ans.InfoHash, err = decodeInfoHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMagnet, err)
+		}
+		break
+	}
+	if ans.InfoHash == "" {
+		return nil, fmt.Errorf("%w: urn:btih not found", ErrInvalidMagnet)
+	}
+	ans.Name = query.Get("dn")
+	if xl := query.Get("xl"); xl != "" {
+		ans.Size, err = strconv.ParseInt(xl, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parse size: %w", ErrInvalidMagnet, err)
+		}
+	}
+	for _, tracker := range query["tr"] {
+		ans.addTracker(tracker)
+	}
+	return &ans, nil
+}
+
+func (ti *TorrentInfo) addTracker(tracker string) {
+	if tracker == "" {
+		return
+	}
+	for _, known := range ti.Trackers {
+		if known == tracker {
+			return
+		}
+	}
+	ti.Trackers = append(ti.Trackers, tracker)
+}
+
+func decodeInfoHash(hash string) (string, error) {
+	var raw []byte
+	var err error
+	switch len(hash) {
+	case hex.EncodedLen(sha1.Size):
+		raw, err = hex.DecodeString(hash)
+	case base32.StdEncoding.EncodedLen(sha1.Size):
+		raw, err = base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+	default:
+		return "", fmt.Errorf("info-hash length %d", len(hash)) //nolint:goerr113
+	}
+	if err != nil {
+		return "", fmt.Errorf("decode info-hash: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func parseTorrentFile(item any) (TorrentFile, error) {
+	file, ok := item.(map[string]any)
+	if !ok {
+		return TorrentFile{}, errors.New("not a dictionary") //nolint:goerr113
+	}
+	length, ok := file["length"].(int64)
+	if !ok || length < 0 {
+		return TorrentFile{}, errors.New("invalid length") //nolint:goerr113
+	}
+	parts, ok := file["path.utf-8"].([]any)
+	if !ok {
+		parts, ok = file["path"].([]any)
+	}
+	if !ok || len(parts) == 0 {
+		return TorrentFile{}, errors.New("invalid path") //nolint:goerr113
+	}
+	var segments = make([]string, 0, len(parts))
+	for _, part := range parts {
+		segment, ok := part.(string)
+		if !ok {
+			return TorrentFile{}, errors.New("invalid path segment") //nolint:goerr113
+		}
+		segments = append(segments, segment)
+	}
+	return TorrentFile{Path: strings.Join(segments, "/"), Size: length}, nil
+}
+
+func bencodeString(dict map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := dict[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// isTorrent checks that chunk (which could be only beginning of file) is bencode dictionary with "info" dictionary.
+// Chunk should be long enough to contain beginning of "info" value.
+func isTorrent(chunk []byte) bool {
+	if len(chunk) == 0 || chunk[0] != 'd' {
+		return false
+	}
+	dec := &bencodeDecoder{data: chunk, pos: 1}
+	for dec.pos < len(dec.data) && dec.data[dec.pos] != 'e' {
+		key, err := dec.decodeString()
+		if err != nil {
+			return false
+		}
+		if key == "info" {
+			return dec.pos < len(dec.data) && dec.data[dec.pos] == 'd'
+		}
+		if _, err := dec.decode(1); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// bencodeDecoder decodes bencode (BEP 3) into int64, string, []any and map[string]any.
+// Raw value of top-level "info" key is kept for info-hash calculation.
+type bencodeDecoder struct {
+	data []byte
+	pos  int
+	info []byte
+}
+
+func (bd *bencodeDecoder) decode(depth int) (any, error) {
+	if depth > maxBencodeDepth {
+		return nil, fmt.Errorf("%w: too deep nesting", ErrInvalidBencode)
+	}
+	if bd.pos >= len(bd.data) {
+		return nil, fmt.Errorf("%w: unexpected end", ErrInvalidBencode)
+	}
+	switch c := bd.data[bd.pos]; {
+	case c == 'i':
+		return bd.decodeInt()
+	case c >= '0' && c <= '9':
+		return bd.decodeString()
+	case c == 'l':
+		bd.pos++
+		var list = []any{}
+		for {
+			if bd.pos >= len(bd.data) {
+				return nil, fmt.Errorf("%w: unterminated list", ErrInvalidBencode)
+			}
+			if bd.data[bd.pos] == 'e' {
+				bd.pos++
+				return list, nil
+			}
+			item, err := bd.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case c == 'd':
+		bd.pos++
+		var dict = map[string]any{}
+		for {
+			if bd.pos >= len(bd.data) {
+				return nil, fmt.Errorf("%w: unterminated dictionary", ErrInvalidBencode)
+			}
+			if bd.data[bd.pos] == 'e' {
+				bd.pos++
+				return dict, nil
+			}
+			key, err := bd.decodeString()
+			if err != nil {
+				return nil, fmt.Errorf("dictionary key: %w", err)
+			}
+			if _, ok := dict[key]; ok {
+				return nil, fmt.Errorf("%w: duplicated key %q", ErrInvalidBencode, key)
+			}
+			start := bd.pos
+			value, err := bd.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if depth == 0 && key == "info" {
+				bd.info = bd.data[start:bd.pos]
+			}
+			dict[key] = value
+		}
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrInvalidBencode, c, bd.pos)
+	}
+}
+
+func (bd *bencodeDecoder) decodeInt() (int64, error) {
+	end := bytes.IndexByte(bd.data[bd.pos:], 'e')
+	if end < 0 {
+		return 0, fmt.Errorf("%w: unterminated integer at %d", ErrInvalidBencode, bd.pos)
+	}
+	raw := string(bd.data[bd.pos+1 : bd.pos+end])
+	digits := strings.TrimPrefix(raw, "-")
+	if digits == "" || (len(digits) > 1 && digits[0] == '0') || raw == "-0" || strings.ContainsAny(digits, "+-") {
+		return 0, fmt.Errorf("%w: malformed integer %q at %d", ErrInvalidBencode, raw, bd.pos)
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: integer at %d: %w", ErrInvalidBencode, bd.pos, err)
+	}
+	bd.pos += end + 1
+	return value, nil
+}
+
+func (bd *bencodeDecoder) decodeString() (string, error) {
+	sep := bytes.IndexByte(bd.data[bd.pos:], ':')
+	if sep <= 0 {
+		return "", fmt.Errorf("%w: malformed string at %d", ErrInvalidBencode, bd.pos)
+	}
+	raw := string(bd.data[bd.pos : bd.pos+sep])
+	if len(raw) > 1 && raw[0] == '0' {
+		return "", fmt.Errorf("%w: malformed string length %q at %d", ErrInvalidBencode, raw, bd.pos)
+	}
+	length, err := strconv.ParseUint(raw, 10, 31)
+	if err != nil {
+		return "", fmt.Errorf("%w: string length at %d: %w", ErrInvalidBencode, bd.pos, err)
+	}
+	start := bd.pos + sep + 1
+	if uint64(len(bd.data)-start) < length {
+		return "", fmt.Errorf("%w: string at %d exceeds data", ErrInvalidBencode, bd.pos)
+	}
+	bd.pos = start + int(length)
+	return string(bd.data[start:bd.pos]), nil
+}
@@ -0,0 +1,119 @@
+package client_test
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/reddec/syno-cli/pkg/client"
+)
+
+const (
+	multiFileInfo = "d5:filesld6:lengthi100e4:pathl5:video9:movie.mkveed6:lengthi20e4:pathl8:subs.srteee" +
+		"4:name5:movie12:piece lengthi16384e6:pieces20:aaaaaaaaaaaaaaaaaaaae"
+	multiFileTorrent = "d13:announce-listll17:udp://tracker/onee" + "l17:udp://tracker/twoee" +
+		"13:creation datei1700000000e4:info" + multiFileInfo + "e"
+	singleFileTorrent = "d8:announce17:udp://tracker/one4:infod6:lengthi42e4:name8:disk.iso" +
+		"12:piece lengthi16384e6:pieces20:bbbbbbbbbbbbbbbbbbbbee"
+)
+
+func TestParseTorrent(t *testing.T) {
+	info, err := client.ParseTorrent([]byte(multiFileTorrent))
+	require.NoError(t, err)
+
+	hash := sha1.Sum([]byte(multiFileInfo)) //nolint:gosec
+	require.Equal(t, hex.EncodeToString(hash[:]), info.InfoHash)
+	require.Equal(t, "movie", info.Name)
+	require.Equal(t, int64(120), info.Size)
+	require.Equal(t, []client.TorrentFile{{Path: "video/movie.mkv", Size: 100}, {Path: "subs.srt", Size: 20}}, info.Files)
+	require.Equal(t, []string{"udp://tracker/one", "udp://tracker/two"}, info.Trackers)
+
+	single, err := client.ParseTorrent([]byte(singleFileTorrent))
+	require.NoError(t, err)
+	require.Equal(t, []client.TorrentFile{{Path: "disk.iso", Size: 42}}, single.Files)
+	require.Equal(t, []string{"udp://tracker/one"}, single.Trackers)
+}
+
+func TestParseTorrent_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":           "",
+		"trailing data":   singleFileTorrent + "x",
+		"truncated":       singleFileTorrent[:40],
+		"leading zero":    "d4:infoi01ee",
+		"negative zero":   "i-0e",
+		"not dictionary":  "l4:infoe",
+		"no info":         "d8:announce3:urle",
+		"duplicated key":  "d4:infoi1e4:infoi2ee",
+		"no pieces":       "d4:infod6:lengthi42e4:name1:a12:piece lengthi1eee",
+		"bad string size": "d4:info99:abce",
+	}
+	for name, payload := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.ParseTorrent([]byte(payload))
+			require.Error(t, err)
+		})
+	}
+	_, err := client.ParseTorrent([]byte("d4:infod6:lengthi42e4:name1:a12:piece lengthi1eee"))
+	require.ErrorIs(t, err, client.ErrInvalidTorrent)
+	_, err = client.ParseTorrent([]byte("d4:info"))
+	require.ErrorIs(t, err, client.ErrInvalidBencode)
+}
+
+func TestTorrentInfo_Select(t *testing.T) {
+	info, err := client.ParseTorrent([]byte(multiFileTorrent))
+	require.NoError(t, err)
+
+	selected, err := info.Select("*.mkv")
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, selected)
+
+	selected, err = info.Select("1", "video/*")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, selected)
+
+	_, err = info.Select("*.avi")
+	require.Error(t, err)
+}
+
+func TestParseMagnet(t *testing.T) {
+	info, err := client.ParseMagnet("magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A&dn=Some+Name&xl=1024&tr=udp%3A%2F%2Ftracker%2Fone&tr=udp%3A%2F%2Ftracker%2Fone")
+	require.NoError(t, err)
+	require.Equal(t, "c12fe1c06bba254a9dc9f519b335aa7c1367a88a", info.InfoHash)
+	require.Equal(t, "Some Name", info.Name)
+	require.Equal(t, int64(1024), info.Size)
+	require.Equal(t, []string{"udp://tracker/one"}, info.Trackers)
+
+	base32, err := client.ParseMagnet("magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK")
+	require.NoError(t, err)
+	require.Equal(t, info.InfoHash, base32.InfoHash)
+
+	for _, uri := range []string{"https://example.com", "magnet:?dn=name", "magnet:?xt=urn:btih:123"} {
+		_, err := client.ParseMagnet(uri)
+		require.ErrorIs(t, err, client.ErrInvalidMagnet, uri)
+	}
+}
+
+func TestDetectFileType(t *testing.T) {
+	torrents := []string{
+		multiFileTorrent,
+		singleFileTorrent,
+		"d4:info" + multiFileInfo + "e",
+		"d5:alias5:movie4:info" + multiFileInfo + "e", // unknown key before info
+		multiFileTorrent[:len(multiFileTorrent)-20],   // beginning of file only
+	}
+	for _, payload := range torrents {
+		ft, err := client.DetectFileType([]byte(payload))
+		require.NoError(t, err)
+		require.Equal(t, client.FileTypeTorrent, ft)
+	}
+	ft, err := client.DetectFileType([]byte("magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A\n"))
+	require.NoError(t, err)
+	require.Equal(t, client.FileTypeTxt, ft)
+
+	for _, payload := range []string{"d3:foo3:bare", "d8:announce3:urle", "d4:infoi1ee", "d8:announce3:url", "d3:foo"} {
+		_, err = client.DetectFileType([]byte(payload))
+		require.ErrorIs(t, err, client.ErrUnknownFileType, payload)
+	}
+}
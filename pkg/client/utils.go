@@ -38,3 +38,23 @@ func (ft *FileType) UnmarshalFlag(value string) error {
 	*ft = v
 	return nil
 }
+
+// UnmarshalFlag is an adapter for go-flags.
+func (ts *TaskStatus) UnmarshalFlag(value string) error {
+	v, err := ParseTaskStatus(value)
+	if err != nil {
+		return err
+	}
+	*ts = v
+	return nil
+}
+
+// UnmarshalFlag is an adapter for go-flags.
+func (tt *TaskType) UnmarshalFlag(value string) error {
+	v, err := ParseTaskType(value)
+	if err != nil {
+		return err
+	}
+	*tt = v
+	return nil
+}